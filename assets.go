@@ -0,0 +1,112 @@
+package lessonmd
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	h "html"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Asset is the stylesheet or a script that goes with the converted HTML.
+type Asset struct {
+	File    string // File name used with ConverterOptions.ExternalAssets, e.g. "lessonmd-tabs.js"
+	Content string
+}
+
+// isStylesheet reports whether the asset is CSS rather than JavaScript.
+func (a Asset) isStylesheet() bool {
+	return strings.HasSuffix(a.File, ".css")
+}
+
+// Integrity returns the Subresource Integrity hash of the asset, for the
+// integrity attribute of the tag that loads it.
+func (a Asset) Integrity() string {
+	sum := sha512.Sum384([]byte(a.Content))
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Assets returns the stylesheet and scripts the options ask for, in the
+// order they're added to the output.
+func (c *converter) Assets(o ConverterOptions) ([]Asset, error) {
+	var assets []Asset
+	if o.AddStyleTag {
+		css, err := c.GenerateThemedCSS(o.WrapperClass, o.Theme, o.ThemeColors)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, Asset{"lessonmd.css", css})
+	}
+	if o.AddHighlightJS {
+		assets = append(assets, Asset{"lessonmd-highlight.js", c.GenerateHighlightJS(o.WrapperClass)})
+	}
+	if o.AddMermaidJS {
+		js, err := c.GenerateThemedMermaidJS(o.MermaidTheme)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, Asset{"lessonmd-mermaid.js", js})
+	}
+	if o.AddTabsJS {
+		assets = append(assets, Asset{"lessonmd-tabs.js", c.GenerateTabsJS(o.WrapperClass)})
+	}
+	if o.AddQuizJS {
+		assets = append(assets, Asset{"lessonmd-quiz.js", c.GenerateQuizJS(o.WrapperClass)})
+	}
+	if o.AddKeysJS {
+		assets = append(assets, Asset{"lessonmd-keys.js", c.GenerateKeysJS(o.WrapperClass)})
+	}
+	if o.AddAsciinemaJS {
+		assets = append(assets, Asset{"lessonmd-asciinema.js", c.GenerateAsciinemaJS(o.WrapperClass)})
+	}
+	return assets, nil
+}
+
+// WriteAssets writes the assets the options ask for to o.ExternalAssets,
+// creating the directory if needed.
+func (c *converter) WriteAssets(o ConverterOptions) error {
+	if o.ExternalAssets == "" {
+		return fmt.Errorf("no directory for external assets")
+	}
+	if err := os.MkdirAll(o.ExternalAssets, 0755); err != nil {
+		return fmt.Errorf("failed to create assets directory: %w", err)
+	}
+	assets, err := c.Assets(o)
+	if err != nil {
+		return err
+	}
+	for _, a := range assets {
+		file := filepath.Join(o.ExternalAssets, a.File)
+		if err := os.WriteFile(file, []byte(a.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// assetTag returns the tag that adds the asset to the page. The asset is
+// inlined unless o.ExternalAssets is set, in which case the tag links to
+// the file with an integrity hash. Every tag gets o.CSPNonce if it's set.
+func (c *converter) assetTag(a Asset, o ConverterOptions) string {
+	nonce := ""
+	if o.CSPNonce != "" {
+		nonce = " nonce=\"" + h.EscapeString(o.CSPNonce) + "\""
+	}
+
+	if o.ExternalAssets != "" {
+		url := h.EscapeString(path.Join(filepath.ToSlash(o.ExternalAssets), a.File))
+		integrity := " integrity=\"" + a.Integrity() + "\" crossorigin=\"anonymous\""
+		if a.isStylesheet() {
+			return "<link rel=\"stylesheet\" href=\"" + url + "\"" + integrity + nonce + ">\n"
+		}
+		return "<script src=\"" + url + "\"" + integrity + nonce + "></script>\n"
+	}
+
+	if a.isStylesheet() {
+		return "<style" + nonce + ">" + a.Content + "</style>\n"
+	}
+	return "<script" + nonce + ">" + a.Content + "</script>\n"
+}
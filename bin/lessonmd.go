@@ -1,11 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"lessonmd"
 	"os"
+	"strings"
 )
 
 func banner() {
@@ -27,12 +29,35 @@ func main() {
 	highlightjs := flag.Bool("include-highlight-js", config.IncludeHighlightJS, "Include script tags to include Highlight.js client-side libraries from CDN and add copy-to-clipboard functionality.")
 	mermaidJS := flag.Bool("include-mermaid-js", config.IncludeMermaidJS, "Include script tags for client-side Mermaid rendering.")
 	tabsJS := flag.Bool("include-tabs-js", config.IncludeTabsJS, "Include script tags for client-side tabs functionality.")
+	quizJS := flag.Bool("include-quiz-js", config.IncludeQuizJS, "Include script tags for client-side quiz answer checking.")
+	keysJS := flag.Bool("include-keys-js", config.IncludeKeysJS, "Include script tags to show platform-specific keyboard shortcuts.")
+	asciinemaJS := flag.Bool("include-asciinema-js", config.IncludeAsciinemaJS, "Include script tags to play embedded asciinema recordings.")
+	theme := flag.String("theme", config.Theme, "Color theme for the stylesheet: "+strings.Join(lessonmd.ThemeNames(), ", ")+".")
 	styleTag := flag.Bool("include-stylesheet", config.IncludeStylesheet, "Include CSS in a <style> tag in the output.")
 	frontmatter := flag.Bool("include-frontmatter", config.IncludeFrontmatter, "Include YAML frontmatter as a table. Defaults to false - frontmatter is omitted.")
 	mermaidSVG := flag.Bool("use-mermaid-svg-renderer", config.UseMermaidSVGRenderer, "Use embedded SVG for Mermaid instead of client-side JavaScript.")
+	mermaidTheme := flag.String("mermaid-theme", config.MermaidTheme, "Mermaid theme, like 'default', 'dark', 'forest', or 'neutral'.")
+	mermaidCLI := flag.String("mermaid-cli", config.MermaidCLI, "Path to the Mermaid CLI (mmdc) used with -use-mermaid-svg-renderer.")
+	diagramCache := flag.String("diagram-cache", config.DiagramCache, "Directory for rendered diagram SVGs, reused while a diagram is unchanged.")
+	diagramAssets := flag.String("diagram-assets", config.DiagramAssets, "Write rendered diagrams to SVG files in this directory and link to them instead of inlining them.")
+	hideSolutions := flag.Bool("hide-solutions", config.HideSolutions, "Remove exercise solutions from the output, e.g. for student handouts.")
+	printMode := flag.Bool("print", config.Print, "Render for printing: every tab panel in turn under its title, and every details block open.")
+	staticTabs := flag.Bool("static-tabs", config.StaticTabs, "Render tabs with radio inputs and CSS so they work without JavaScript.")
+	idPrefix := flag.String("id-prefix", config.IDPrefix, "Prefix added to every generated id, so several lessons can share one page.")
+	numberHeadings := flag.Bool("number-headings", config.NumberHeadings, "Number headings hierarchically, e.g. 1, 1.1, 1.2.")
+	safeMode := flag.Bool("safe", config.Safe, "Render untrusted Markdown safely: remove raw HTML, dangerous links, and unknown attributes.")
+	allowedTags := flag.String("allowed-tags", "", "Comma-separated list of raw HTML tags to keep in safe mode, like 'kbd,sup'.")
+	allowedAttributes := flag.String("allowed-attributes", "", "Comma-separated list of attributes to keep in safe mode, like 'id,class,data-*'.")
+	cspNonce := flag.String("csp-nonce", "", "Nonce to add to every <script> and <style> tag for a Content Security Policy.")
+	externalAssets := flag.String("external-assets", config.ExternalAssets, "Write the CSS and JavaScript to files in this directory and link to them instead of inlining them.")
+	extensions := flag.String("extensions", "", "Comma-separated list of extensions to turn on, or off with a leading '-', e.g. 'typographer,-tabs'. Available: "+strings.Join(lessonmd.ExtensionNames(), ", ")+".")
 	printMermaid := flag.Bool("print-mermaid-js", false, "Print the JavaScript code for Mermaid support.")
 	printHighlight := flag.Bool("print-highlight-js", false, "Print the JavaScript code for client-side syntax and clipboard support.")
 	printTabs := flag.Bool("print-tabs-js", false, "Print the JavaScript code for client-side tabs functionality.")
+	printQuiz := flag.Bool("print-quiz-js", false, "Print the JavaScript code for client-side quiz answer checking.")
+	printKeys := flag.Bool("print-keys-js", false, "Print the JavaScript code for platform-specific keyboard shortcuts.")
+	printAsciinema := flag.Bool("print-asciinema-js", false, "Print the JavaScript code for playing embedded asciinema recordings.")
+	printAnswers := flag.Bool("print-quiz-answers", false, "Print the quiz answer key for the document as JSON instead of HTML.")
 	printCSS := flag.Bool("print-stylesheet", false, "Print the CSS file to standard output. Provide optional parent class. (defaults to 'item' - use `-c` to change.)")
 
 	flag.Parse()
@@ -54,7 +79,11 @@ func main() {
 	}
 
 	if *printMermaid {
-		out := lessonmd.Converter.GenerateMermaidJS()
+		out, err := lessonmd.Converter.GenerateThemedMermaidJS(*mermaidTheme)
+		if err != nil {
+			io.WriteString(os.Stderr, "Unable to generate Mermaid script: "+err.Error()+"\n")
+			os.Exit(1)
+		}
 		io.WriteString(os.Stdout, out)
 		os.Exit(0)
 	}
@@ -65,8 +94,30 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *printQuiz {
+		out := lessonmd.Converter.GenerateQuizJS(*wrapperClass)
+		io.WriteString(os.Stdout, out)
+		os.Exit(0)
+	}
+
+	if *printKeys {
+		out := lessonmd.Converter.GenerateKeysJS(*wrapperClass)
+		io.WriteString(os.Stdout, out)
+		os.Exit(0)
+	}
+
+	if *printAsciinema {
+		out := lessonmd.Converter.GenerateAsciinemaJS(*wrapperClass)
+		io.WriteString(os.Stdout, out)
+		os.Exit(0)
+	}
+
 	if *printCSS {
-		css := lessonmd.Converter.GenerateCSS(*wrapperClass)
+		css, err := lessonmd.Converter.GenerateThemedCSS(*wrapperClass, *theme, config.ThemeColors)
+		if err != nil {
+			io.WriteString(os.Stderr, "Unable to generate stylesheet: "+err.Error()+"\n")
+			os.Exit(1)
+		}
 		io.WriteString(os.Stdout, css)
 		os.Exit(0)
 	}
@@ -85,9 +136,66 @@ func main() {
 		AddStyleTag:        *styleTag,
 		AddHighlightJS:     *highlightjs,
 		UseSVGforMermaid:   *mermaidSVG,
+		MermaidTheme:       *mermaidTheme,
+		MermaidCLI:         *mermaidCLI,
+		MermaidArgs:        config.MermaidArgs,
+		DiagramCache:       *diagramCache,
+		DiagramCommands:    config.DiagramCommands,
+		DiagramAssets:      *diagramAssets,
 		AddMermaidJS:       *mermaidJS,
 		AddTabsJS:          *tabsJS,
+		AddQuizJS:          *quizJS,
+		AddKeysJS:          *keysJS,
+		AddAsciinemaJS:     *asciinemaJS,
 		IncludeFrontmatter: *frontmatter,
+		HideSolutions:      *hideSolutions,
+		Print:              *printMode,
+		StaticTabs:         *staticTabs,
+		IDPrefix:           *idPrefix,
+		NumberHeadings:     *numberHeadings,
+		Safe:               *safeMode,
+		AllowedTags:        config.AllowedTags,
+		AllowedAttributes:  config.AllowedAttributes,
+		CSPNonce:           *cspNonce,
+		ExternalAssets:     *externalAssets,
+		Theme:              *theme,
+		ThemeColors:        config.ThemeColors,
+		Extensions:         config.Extensions,
+	}
+
+	// Allowed tags and attributes from the command line replace the config file's lists.
+	if *allowedTags != "" {
+		o.AllowedTags = strings.Split(*allowedTags, ",")
+	}
+	if *allowedAttributes != "" {
+		o.AllowedAttributes = strings.Split(*allowedAttributes, ",")
+	}
+
+	// Extensions from the command line override the config file.
+	if *extensions != "" {
+		o.Extensions = map[string]bool{}
+		for name, enabled := range config.Extensions {
+			o.Extensions[name] = enabled
+		}
+		for name, enabled := range lessonmd.ParseExtensionList(*extensions) {
+			o.Extensions[name] = enabled
+		}
+	}
+
+	if *printAnswers {
+		answers, err := lessonmd.Converter.QuizAnswerKey(markdown, o)
+		if err != nil {
+			io.WriteString(os.Stderr, "Unable to convert file: "+err.Error()+"\n")
+			os.Exit(1)
+		}
+		key, err := json.MarshalIndent(answers, "", "  ")
+		if err != nil {
+			io.WriteString(os.Stderr, "Unable to generate answer key: "+err.Error()+"\n")
+			os.Exit(1)
+		}
+		os.Stdout.Write(key)
+		io.WriteString(os.Stdout, "\n")
+		os.Exit(0)
 	}
 
 	out, err := lessonmd.Converter.Run(markdown, o)
@@ -97,6 +205,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	if o.ExternalAssets != "" {
+		if err := lessonmd.Converter.WriteAssets(o); err != nil {
+			io.WriteString(os.Stderr, "Unable to write assets: "+err.Error()+"\n")
+			os.Exit(1)
+		}
+	}
+
 	io.WriteString(os.Stdout, out)
 }
 
@@ -15,9 +15,30 @@ type Config struct {
 	IncludeHighlightJS   bool   `yaml:"include-highlight-js"`
 	IncludeMermaidJS     bool   `yaml:"include-mermaid-js"`
 	IncludeTabsJS        bool   `yaml:"include-tabs-js"`
+	IncludeQuizJS        bool   `yaml:"include-quiz-js"`
+	IncludeKeysJS        bool   `yaml:"include-keys-js"`
+	IncludeAsciinemaJS   bool   `yaml:"include-asciinema-js"`
 	IncludeStylesheet    bool   `yaml:"include-stylesheet"`
 	IncludeFrontmatter   bool   `yaml:"include-frontmatter"`
 	UseMermaidSVGRenderer bool  `yaml:"use-mermaid-svg-renderer"`
+	MermaidTheme         string   `yaml:"mermaid-theme"`
+	MermaidCLI           string   `yaml:"mermaid-cli"`
+	MermaidArgs          []string `yaml:"mermaid-args"`
+	DiagramCache         string   `yaml:"diagram-cache"`
+	DiagramCommands      map[string]string `yaml:"diagram-commands"`
+	DiagramAssets        string            `yaml:"diagram-assets"`
+	HideSolutions        bool   `yaml:"hide-solutions"`
+	Print                bool   `yaml:"print"`
+	StaticTabs           bool   `yaml:"static-tabs"`
+	IDPrefix             string `yaml:"id-prefix"`
+	NumberHeadings       bool   `yaml:"number-headings"`
+	Safe                 bool     `yaml:"safe"`
+	AllowedTags          []string `yaml:"allowed-tags"`
+	AllowedAttributes    []string `yaml:"allowed-attributes"`
+	ExternalAssets       string   `yaml:"external-assets"`
+	Theme                string            `yaml:"theme"`
+	ThemeColors          map[string]string `yaml:"theme-colors"`
+	Extensions           map[string]bool `yaml:"extensions"`
 }
 
 // DefaultConfig returns a config with default values
@@ -28,9 +49,24 @@ func DefaultConfig() *Config {
 		IncludeHighlightJS:   false,
 		IncludeMermaidJS:     false,
 		IncludeTabsJS:        false,
+		IncludeQuizJS:        false,
+		IncludeKeysJS:        false,
+		IncludeAsciinemaJS:   false,
 		IncludeStylesheet:    false,
 		IncludeFrontmatter:   false,
 		UseMermaidSVGRenderer: false,
+		MermaidTheme:         "",
+		MermaidCLI:           "",
+		DiagramCache:         "",
+		DiagramAssets:        "",
+		HideSolutions:        false,
+		Print:                false,
+		StaticTabs:           false,
+		IDPrefix:             "",
+		NumberHeadings:       false,
+		Safe:                 false,
+		ExternalAssets:       "",
+		Theme:                "light",
 	}
 }
 
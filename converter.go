@@ -2,20 +2,20 @@ package lessonmd
 
 import (
 	"bytes"
-	"lessonmd/extensions/commandblocks"
-	"lessonmd/extensions/details"
-	"lessonmd/extensions/inlinehighlight"
-	"lessonmd/extensions/notices"
-	"lessonmd/extensions/outputblocks"
-	"lessonmd/extensions/tabs"
+	"fmt"
+	"lessonmd/extensions/headingnumbers"
+	"lessonmd/extensions/idprefix"
+	"lessonmd/extensions/quiz"
+	"lessonmd/extensions/safe"
+	"lessonmd/extensions/xref"
 	"strings"
 
 	"github.com/yuin/goldmark"
 	meta "github.com/yuin/goldmark-meta"
-	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/renderer/html"
-	"go.abhg.dev/goldmark/mermaid"
+	"github.com/yuin/goldmark/text"
 )
 
 // AppVersion is the version of the app itself
@@ -30,9 +30,31 @@ type ConverterOptions struct {
 	AddStyleTag        bool
 	AddHighlightJS     bool
 	UseSVGforMermaid   bool
+	MermaidTheme       string            // Mermaid theme, like "forest", for both client-side and SVG rendering
+	MermaidCLI         string            // Path to the Mermaid CLI (mmdc) for SVG rendering
+	MermaidArgs        []string          // Extra arguments for the Mermaid CLI
+	DiagramCache       string            // Keep rendered SVGs in this directory and reuse them while the diagram is unchanged
+	DiagramCommands    map[string]string // Commands that render code fences to SVG by language, like "dot": "dot -Tsvg"
+	DiagramAssets      string            // Write rendered diagrams to SVG files in this directory and link to them instead of inlining them
 	AddMermaidJS       bool
 	AddTabsJS          bool
+	AddQuizJS          bool
+	AddKeysJS          bool
+	AddAsciinemaJS     bool
 	IncludeFrontmatter bool
+	HideSolutions      bool
+	Print              bool   // Render for printing: every tab panel in turn and every details block open
+	StaticTabs         bool   // Render tabs with radio inputs that work without JavaScript
+	IDPrefix           string // Added to the start of every generated id
+	NumberHeadings     bool
+	Safe               bool              // Render untrusted Markdown: no raw HTML, dangerous URLs, or unknown attributes
+	AllowedTags        []string          // Raw HTML tags allowed in safe mode. Defaults to safe.DefaultAllowedTags
+	AllowedAttributes  []string          // Attributes allowed in safe mode. Defaults to safe.DefaultAllowedAttributes
+	CSPNonce           string            // Added as the nonce attribute of every <script> and <style>
+	ExternalAssets     string            // Link to the CSS and JavaScript in this directory instead of inlining them
+	Theme              string            // light (the default), dark, high-contrast, or auto
+	ThemeColors        map[string]string // Overrides the theme's colors by name, like "link"
+	Extensions         map[string]bool   // Turns extensions on (true) or off (false) by name
 }
 
 type converter struct{}
@@ -40,24 +62,15 @@ type converter struct{}
 // Converter converts markdown to HTML
 var Converter = &converter{}
 
-// Run does the conversion, using ConverterOptions. Takes a byte slice (usually from a reader) and returns a string.
-func (c *converter) Run(markdown []byte, o ConverterOptions) (string, error) {
-
-	mmRenderMode := mermaid.RenderModeClient
-
-	if o.UseSVGforMermaid {
-		mmRenderMode = mermaid.RenderModeServer
+// newMarkdown builds the goldmark instance for the given options.
+func (c *converter) newMarkdown(o ConverterOptions) (goldmark.Markdown, error) {
+	if err := checkMermaidTheme(o.MermaidTheme); err != nil {
+		return nil, err
 	}
 
-	var extensions = []goldmark.Extender{
-		extension.GFM, // builtin
-		&mermaid.Extender{NoScript: true, RenderMode: mmRenderMode}, // imported
-		outputblocks.OutputExtender,                                 // custom -> outputblocks.go
-		inlinehighlight.InlineHighlighter,                           // custom -> inlinehighlight.go
-		commandblocks.CommandExtender,                               // custom -> commandblokcs.go
-		notices.AdmonitionExtender,
-		details.DetailsExtender,
-		tabs.TabsExtender,
+	extensions, err := enabledExtensions(o)
+	if err != nil {
+		return nil, err
 	}
 
 	if o.IncludeFrontmatter {
@@ -66,31 +79,66 @@ func (c *converter) Run(markdown []byte, o ConverterOptions) (string, error) {
 		extensions = append(extensions, meta.Meta)
 	}
 
-	md := goldmark.New(
+	if o.NumberHeadings {
+		extensions = append(extensions, headingnumbers.HeadingNumbersExtender)
+	}
+
+	// runs after every other transformer so it sees all the ids
+	extensions = append(extensions, &idprefix.Extender{Prefix: o.IDPrefix})
+
+	var rendererOptions []renderer.Option
+	if o.Safe {
+		extensions = append(extensions, &safe.Extender{
+			AllowedTags:       o.AllowedTags,
+			AllowedAttributes: o.AllowedAttributes,
+		})
+	} else {
+		rendererOptions = append(rendererOptions, html.WithUnsafe()) // allow raw html
+	}
+
+	return goldmark.New(
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 			parser.WithAttribute(),
 		),
-		goldmark.WithRendererOptions(
-			html.WithUnsafe(), // allow raw html
-		),
+		goldmark.WithRendererOptions(rendererOptions...),
 		goldmark.WithExtensions(extensions...),
-	)
+	), nil
+}
+
+// Run does the conversion, using ConverterOptions. Takes a byte slice (usually from a reader) and returns a string.
+func (c *converter) Run(markdown []byte, o ConverterOptions) (string, error) {
+	md, err := c.newMarkdown(o)
+	if err != nil {
+		return "", err
+	}
 
 	var html bytes.Buffer
 	// Convert Markdown to HTML
-	err := md.Convert(markdown, &html)
+	pc := parser.NewContext()
+	err = md.Convert(markdown, &html, parser.WithContext(pc))
 
 	if err != nil {
 		return "", err
 	}
 
+	// fail if any cross-references point at something that doesn't exist
+	if err := xref.Error(pc); err != nil {
+		return "", err
+	}
+
 	out := html.String()
 
-	// add a style tag with css code at the top if reqeusted (default is no)
-	if o.AddStyleTag {
-		out = c.addCSS(o.WrapperClass) + out + "\n"
+	assets, err := c.Assets(o)
+	if err != nil {
+		return "", err
+	}
 
+	// add a style tag with css code at the top if reqeusted (default is no)
+	for _, a := range assets {
+		if a.isStylesheet() {
+			out = c.assetTag(a, o) + out + "\n"
+		}
 	}
 
 	// add the wrapper class if requested (default is yes)
@@ -98,34 +146,46 @@ func (c *converter) Run(markdown []byte, o ConverterOptions) (string, error) {
 		out = "<div class=\"" + o.WrapperClass + "\">\n" + out + "\n</div>"
 	}
 
-	// add the highlight.js and clipboard code snippet at the bottom if requested (default is no)
-	if o.AddHighlightJS {
-		out = out + c.addHighlightJS(o.WrapperClass)
-	}
-
-	// add the mermaid.js code snippet at the bottom if requested (default is no)
-	if o.AddMermaidJS {
-		out = out + c.addMermaidJS()
-	}
-
-	// add the tabs.js code snippet at the bottom if requested (default is no)
-	if o.AddTabsJS {
-		out = out + c.addTabsJS(o.WrapperClass)
+	// add the requested scripts at the bottom (default is none)
+	for _, a := range assets {
+		if !a.isStylesheet() {
+			out = out + c.assetTag(a, o)
+		}
 	}
 
 	// Print HTML to standard output
 	return out, nil
 }
 
-func (c *converter) addCSS(class string) string {
-	return "<style>" + c.GenerateCSS(class) + "</style>\n"
+// QuizAnswerKey parses the document and returns the answer key for every quiz in it,
+// so an LMS can grade submissions without scraping the HTML.
+func (c *converter) QuizAnswerKey(markdown []byte, o ConverterOptions) ([]quiz.Question, error) {
+	md, err := c.newMarkdown(o)
+	if err != nil {
+		return nil, err
+	}
+	pc := parser.NewContext()
+	md.Parser().Parse(text.NewReader(markdown), parser.WithContext(pc))
+	return quiz.GetAnswerKey(pc), nil
 }
 
-// GenerateCSS returns a string with the basic stylesheet.
+// GenerateCSS returns a string with the basic stylesheet, using the light theme.
 func (c *converter) GenerateCSS(class string) string {
-	style := `
+	style, _ := c.GenerateThemedCSS(class, "light", nil)
+	return style
+}
+
+// GenerateThemedCSS returns the stylesheet using one of the built-in themes,
+// with any of the theme's colors overridden.
+func (c *converter) GenerateThemedCSS(class, theme string, colors map[string]string) (string, error) {
+	if err := checkTheme(theme, colors); err != nil {
+		return "", err
+	}
+
+	style := "\n" + themeVariables(theme, colors) + `
 .item {
-  color: #333;
+  background-color: var(--lessonmd-background);
+  color: var(--lessonmd-text);
   direction: ltr;
   font-family: -apple-system,BlinkMacSystemFont,Segoe UI,Helvetica,Arial,sans-serif,Apple Color Emoji,Segoe UI Emoji;
   font-size: 16px;
@@ -134,7 +194,7 @@ func (c *converter) GenerateCSS(class string) string {
   word-wrap: break-word
 }
 
-.item a { color: #0969da; }
+.item a { color: var(--lessonmd-link); }
 
 .item code,.item pre{
   font-family:Monaco, Andale Mono, Courier New, monospace;
@@ -145,15 +205,15 @@ func (c *converter) GenerateCSS(class string) string {
 }
 
 .item code{
-  background-color:#fafafa;
-  border: 1px solid #e1e1e8;
+  background-color:var(--lessonmd-code-background);
+  border: 1px solid var(--lessonmd-code-border);
   border-radius: 3px;
   font-weight:bolder;
   white-space: nowrap;
 }
 
 .item pre>code {
-  color: #000;
+  color: var(--lessonmd-code-text);
   display:block;
   font-size:12px;
   line-height:18px;
@@ -169,7 +229,7 @@ func (c *converter) GenerateCSS(class string) string {
   margin-bottom: 16px
 }
 
-.item blockquote{ padding: 0 1em; color: #6a737d; border-left: .25em solid #dfe2e5 }
+.item blockquote{ padding: 0 1em; color: var(--lessonmd-muted); border-left: .25em solid var(--lessonmd-quote-border) }
 .item blockquote>:first-child { margin-top: 0 }
 .item blockquote>:last-child { margin-top: 0 }
 
@@ -181,11 +241,11 @@ func (c *converter) GenerateCSS(class string) string {
 }
 
 .item h1 { font-size: 2em }
-.item h1, .item h2 { padding-bottom: .3em; border-bottom: 1px solid #eaecef }
+.item h1, .item h2 { padding-bottom: .3em; border-bottom: 1px solid var(--lessonmd-rule) }
 .item h3 { font-size: 1.25em }
 .item h4 { font-size: 1em }
 .item h5 { font-size: .875em }
-.item h6 { font-size: .85em; color: #6a737d }
+.item h6 { font-size: .85em; color: var(--lessonmd-muted) }
 .item ol,.item ul { padding-left: 2em }
 
 .item ol ol, .item ol ul, .item ul ol, .item ul ul { margin-top: 0; margin-bottom: 0 }
@@ -198,18 +258,48 @@ func (c *converter) GenerateCSS(class string) string {
 .item dl dt { padding: 0; margin-top: 16px; font-size: 1em; font-style: italic; font-weight: 600 }
 .item dl dd { padding: 0 16px; margin-bottom: 16px }
 
-.item table{width:100%;margin-bottom:18px;padding:0;border-collapse:separate;*border-collapse:collapse;font-size:13px;border:1px solid #ddd;-webkit-border-radius:4px;-moz-border-radius:4px;border-radius:4px;}table th,table td{padding:10px 10px 9px;line-height:18px;text-align:left;}
-.item table th{padding-top:9px;font-weight:bold;vertical-align:middle;border-bottom:1px solid #ddd;color:ddd;background-color:#333;}
+.item .footnotes { border-top: 1px solid var(--lessonmd-rule); color: var(--lessonmd-muted); font-size: .875em; margin-top: 32px; }
+.item .footnotes hr { display: none; }
+.item sup a.footnote-ref { text-decoration: none; }
+
+.item table{width:100%;margin-bottom:18px;padding:0;border-collapse:separate;*border-collapse:collapse;font-size:13px;border:1px solid var(--lessonmd-border);-webkit-border-radius:4px;-moz-border-radius:4px;border-radius:4px;}table th,table td{padding:10px 10px 9px;line-height:18px;text-align:left;}
+.item table th{padding-top:9px;font-weight:bold;vertical-align:middle;border-bottom:1px solid var(--lessonmd-border);color:var(--lessonmd-table-header-text);background-color:var(--lessonmd-table-header-background);}
 .item table td{vertical-align:top;}
-.item table th+th, .item table td+td{border-left:1px solid #ddd;}
-.item table tr+tr td{border-top:1px solid #ddd;}
+.item table th+th, .item table td+td{border-left:1px solid var(--lessonmd-border);}
+.item table tr+tr td{border-top:1px solid var(--lessonmd-border);}
 .item table tbody tr:first-child td:first-child{-webkit-border-radius:4px 0 0 0;-moz-border-radius:4px 0 0 0;border-radius:4px 0 0 0;}
 .item table tbody tr:first-child td:last-child{-webkit-border-radius:0 4px 0 0;-moz-border-radius:0 4px 0 0;border-radius:0 4px 0 0;}
 .item table tbody tr:last-child td:first-child{-webkit-border-radius:0 0 0 4px;-moz-border-radius:0 0 0 4px;border-radius:0 0 0 4px;}
 .item table tbody tr:last-child td:last-child{-webkit-border-radius:0 0 4px 0;-moz-border-radius:0 0 4px 0;border-radius:0 0 4px 0;}
-.item table tr:nth-child(even) { background-color: #e5e5e5; }
+.item table tr:nth-child(even) { background-color: var(--lessonmd-table-stripe); }
+.item table caption { caption-side: top; color: var(--lessonmd-muted); font-size: .9em; margin-bottom: 8px; text-align: left; }
+.item .csv-table { overflow-x: auto; }
+.item .csv-table-note { color: var(--lessonmd-muted); font-size: .9em; margin-top: -10px; }
 
 .item img { max-width: 100%; box-sizing: initial; background-color: #fff }
+
+.item figure { margin: 0 0 16px 0; }
+.item figure.figure-image { text-align: center; }
+.item figcaption { color: var(--lessonmd-muted); font-size: .9em; margin: 8px 0; }
+.item figure.figure-image figcaption { margin-top: 8px; }
+.item .figure-label { font-weight: 600; }
+.item .diagram { margin: 0 0 16px 0; overflow-x: auto; text-align: center; }
+.item .diagram svg { max-width: 100%; height: auto; }
+.item .ascii-diagram { text-align: center; }
+.item .ascii-diagram svg { max-width: 100%; height: auto; }
+.item .ascii-diagram-source { text-align: left; color: var(--lessonmd-muted); font-size: .9em; }
+.item .chart { text-align: center; }
+.item .chart svg { max-width: 100%; height: auto; }
+.item .chart-grid { stroke: var(--lessonmd-rule); }
+.item .chart-axis { stroke: var(--lessonmd-muted); }
+.item .chart-color-1 { fill: var(--lessonmd-chart-1); stroke: var(--lessonmd-chart-1); }
+.item .chart-color-2 { fill: var(--lessonmd-chart-2); stroke: var(--lessonmd-chart-2); }
+.item .chart-color-3 { fill: var(--lessonmd-chart-3); stroke: var(--lessonmd-chart-3); }
+.item .chart-color-4 { fill: var(--lessonmd-chart-4); stroke: var(--lessonmd-chart-4); }
+.item .chart-color-5 { fill: var(--lessonmd-chart-5); stroke: var(--lessonmd-chart-5); }
+.item .chart-color-6 { fill: var(--lessonmd-chart-6); stroke: var(--lessonmd-chart-6); }
+.item .chart-data { text-align: left; }
+.item .heading-number { color: var(--lessonmd-muted); margin-right: .25em; }
 .item strong { font-weight: bolder }
 
 .item .hljs-copy {
@@ -222,19 +312,62 @@ func (c *converter) GenerateCSS(class string) string {
   font-weight: bolder;
 }
 
-.item .output { background-color: #ddd; }
+.item pre.tree > code { white-space: pre; }
+.item .tree-folder::before { content: "\1F4C1\00A0"; }
+.item .tree-file::before { content: "\1F4C4\00A0"; }
+.item .tree-highlight { background-color: var(--lessonmd-highlight); font-weight: bolder; }
+.item .tree-comment { color: var(--lessonmd-muted); font-weight: normal; }
+
+.item pre.diff > code { white-space: pre; }
+.item .diff-line { display: block; }
+.item .diff-line::before { content: "  "; color: var(--lessonmd-muted); }
+.item .diff-add { background-color: var(--lessonmd-diff-add); }
+.item .diff-add::before { content: "+ "; }
+.item .diff-del { background-color: var(--lessonmd-diff-del); }
+.item .diff-del::before { content: "- "; }
+.item .diff-hunk { color: var(--lessonmd-muted); background-color: var(--lessonmd-diff-hunk); }
+.item .diff-hunk::before { content: ""; }
+.item .diff-header { color: var(--lessonmd-muted); background-color: var(--lessonmd-diff-hunk); font-weight: bold; }
+.item .diff-header::before { content: ""; }
+.item .diff-split { display: flex; gap: 8px; }
+.item .diff-split .diff-pane { flex: 1; min-width: 0; }
+.item .diff-split pre { overflow-x: auto; }
+.item .diff-pane-label { font-size: .875em; font-weight: 600; color: var(--lessonmd-muted); margin-bottom: 4px; }
+
+.item .callout {
+  background-color: var(--lessonmd-accent);
+  border-radius: 50%;
+  color: var(--lessonmd-accent-text);
+  display: inline-block;
+  font-family: -apple-system,BlinkMacSystemFont,Segoe UI,Helvetica,Arial,sans-serif;
+  font-size: 11px;
+  font-weight: 600;
+  height: 1.5em;
+  line-height: 1.5em;
+  margin-left: .5em;
+  text-align: center;
+  text-decoration: none;
+  user-select: none;
+  width: 1.5em;
+}
+.item .callout::before { content: attr(data-callout); }
+.item ol.callout-list { list-style: none; padding-left: 0; }
+.item .callout-list .callout { margin: 0 .25em 0 0; }
+.item .callout-list li:target { background-color: var(--lessonmd-highlight); }
+
+.item .output { background-color: var(--lessonmd-output-background); }
 .item .output p { margin: 0 0 0 4px}
 
 .item mark {
-  background-color: #fff8c5;
-  color: #24292f;
+  background-color: var(--lessonmd-highlight);
+  color: var(--lessonmd-highlight-text);
 }
 
 .item .notice {
   border-style: solid;
   border-width: 0 0 0 5px;
-  box-shadow: 0 1px 2px 0 #ddd;
-  color: #193c47;
+  box-shadow: 0 1px 2px 0 var(--lessonmd-shadow);
+  color: var(--lessonmd-info-text);
   margin-bottom: 1em;
   padding: 0.5rem;
 }
@@ -247,50 +380,50 @@ func (c *converter) GenerateCSS(class string) string {
 }
 
 .item .notice.note {
-  background-color: rgb(253, 253, 254);
-  border-color: rgb(212, 213, 216);
-  color: rgb(71, 71, 72)
+  background-color: var(--lessonmd-note-background);
+  border-color: var(--lessonmd-note-border);
+  color: var(--lessonmd-note-text)
 }
-.item .notice.note a{color: rgb(71, 71, 72); text-decoration-color: rgb(212, 213, 216)}
+.item .notice.note a {color: var(--lessonmd-note-text); text-decoration-color: var(--lessonmd-note-border)}
 
 .item .notice.tip {
-  background-color: rgb(230, 246, 230);
-  border-color: rgb(0, 148, 0);
-  color: rgb(0, 49, 0);
+  background-color: var(--lessonmd-tip-background);
+  border-color: var(--lessonmd-tip-border);
+  color: var(--lessonmd-tip-text);
 }
 .item .notice.tip code {background-color: rgba(0, 164, 0, 0.15)}
-.item .notice.tip a {color: rgb(0, 49, 0); text-decoration-color: #009400}
+.item .notice.tip a {color: var(--lessonmd-tip-text); text-decoration-color: var(--lessonmd-tip-border)}
 
 .item .notice.info {
-  background-color: rgb(238, 249, 253);
-  border-color: rgb(76, 179, 212);
-  color: rgb(25, 60, 71)
+  background-color: var(--lessonmd-info-background);
+  border-color: var(--lessonmd-info-border);
+  color: var(--lessonmd-info-text)
 }
 .item .notice.info code {background-color: rgba(84, 199, 236, 0.15)}
-.item .notice.info a {color: rgb(25, 60, 71); text-decoration-color: #4CB3D4}
+.item .notice.info a {color: var(--lessonmd-info-text); text-decoration-color: var(--lessonmd-info-border)}
 
 .item .notice.caution {
-  background-color: #fff8e6;
-  border-color: #e6a700;
-  color: rgb(77, 56, 0)
+  background-color: var(--lessonmd-caution-background);
+  border-color: var(--lessonmd-caution-border);
+  color: var(--lessonmd-caution-text)
 }
 .item .notice.caution code {background-color: rgba(255, 186, 0, 0.15)}
-.item .notice.caution a {color: rgb(77, 56, 0); text-decoration-color: #e6a700}
+.item .notice.caution a {color: var(--lessonmd-caution-text); text-decoration-color: var(--lessonmd-caution-border)}
 
 .item .notice.warning {
-  background-color: #FFEBEC;
-  border-color: #E13238;
-  color: #4b1113;
+  background-color: var(--lessonmd-warning-background);
+  border-color: var(--lessonmd-warning-border);
+  color: var(--lessonmd-warning-text);
 }
 .item .notice.warning code {background-color: rgba(250, 56, 62, 0.15)}
-.item .notice.warning a {  color: #4b1113; text-decoration-color: #4b1113}
+.item .notice.warning a {color: var(--lessonmd-warning-text); text-decoration-color: var(--lessonmd-warning-border)}
 
 .item details {
-  background-color: rgb(238, 249, 253);
-  border: 1px solid rgb(76, 179, 212);
+  background-color: var(--lessonmd-info-background);
+  border: 1px solid var(--lessonmd-info-border);
   border-radius: 10px;
-  box-shadow: 0 1px 2px 0 #ddd;
-  color: #193c47;
+  box-shadow: 0 1px 2px 0 var(--lessonmd-shadow);
+  color: var(--lessonmd-info-text);
   margin-bottom: 1em;
 }
 
@@ -299,14 +432,14 @@ func (c *converter) GenerateCSS(class string) string {
 }
 
 .item details .details-content {
-  border-top: 1px solid rgb(76, 179, 212);
+  border-top: 1px solid var(--lessonmd-info-border);
   margin: 10px;
   padding-top: 10px;
 }
 
 .item details summary {
   border-radius: 10px;
-  color: rgb(25, 60, 71);
+  color: var(--lessonmd-info-text);
   cursor: pointer;
   list-style: none;
   padding: 10px;
@@ -318,15 +451,15 @@ func (c *converter) GenerateCSS(class string) string {
 .item details[open] summary:before { content: "\25BC"; } /* Unicode escape sequence for ▼ */
 
 .item .tabs {
-  border: 1px solid #ddd;
+  border: 1px solid var(--lessonmd-border);
   border-radius: 4px;
   margin: 1em 0;
 }
 
 .item .tabs-nav {
   display: flex;
-  background: #f8f9fa;
-  border-bottom: 1px solid #ddd;
+  background: var(--lessonmd-surface);
+  border-bottom: 1px solid var(--lessonmd-border);
   overflow-x: auto;
 }
 
@@ -336,18 +469,18 @@ func (c *converter) GenerateCSS(class string) string {
   padding: 0.75em 1em;
   cursor: pointer;
   white-space: nowrap;
-  color: #0969da;
+  color: var(--lessonmd-link);
   font-size: 14px;
 }
 
 .item .tab-button:hover {
-  background: #e9ecef;
+  background: var(--lessonmd-surface-hover);
 }
 
 .item .tab-button.active {
-  background: #fff;
-  border-bottom: 2px solid #0969da;
-  color: #24292f;
+  background: var(--lessonmd-background);
+  border-bottom: 2px solid var(--lessonmd-accent);
+  color: var(--lessonmd-text);
   font-weight: 600;
 }
 
@@ -359,25 +492,210 @@ func (c *converter) GenerateCSS(class string) string {
 .item .tab-panel.active {
   display: block;
 }
+
+.item .tabs-print .tab-panel { display: block; }
+.item .tabs-print .tab-panel + .tab-panel { border-top: 1px solid var(--lessonmd-border); }
+.item .tab-title { margin-top: 0; }
+
+.item .tabs-static { display: flex; flex-wrap: wrap; }
+.item .tabs-static > .tab-input { position: absolute; opacity: 0; }
+
+.item .tabs-static > .tab-label {
+  background: var(--lessonmd-surface);
+  border-bottom: 1px solid var(--lessonmd-border);
+  color: var(--lessonmd-link);
+  cursor: pointer;
+  font-size: 14px;
+  padding: 0.75em 1em;
+  white-space: nowrap;
+}
+
+.item .tabs-static > .tab-label:hover { background: var(--lessonmd-surface-hover); }
+
+.item .tabs-static > .tab-input:checked + .tab-label {
+  background: var(--lessonmd-background);
+  border-bottom: 2px solid var(--lessonmd-accent);
+  color: var(--lessonmd-text);
+  font-weight: 600;
+}
+
+.item .tabs-static > .tab-input:focus-visible + .tab-label { outline: 2px solid var(--lessonmd-accent); }
+.item .tabs-static > .tab-panel { order: 1; width: 100%; }
+.item .tabs-static > .tab-input:checked + .tab-label + .tab-panel { display: block; }
+
+.item .exercise {
+  border: 1px solid var(--lessonmd-border);
+  border-radius: 4px;
+  box-shadow: 0 1px 2px 0 var(--lessonmd-shadow);
+  margin-bottom: 1em;
+  padding: 0.5rem;
+}
+
+.item .exercise .exercise-heading {
+  font-size: 0.9em;
+  font-weight: bolder;
+  text-transform: uppercase;
+  margin-bottom: 1em;
+}
+
+.item .exercise details { margin-bottom: .5em; }
+.item .exercise details details { margin: 10px 0 0 0; }
+
+.item .exercise details.exercise-solution {
+  background-color: var(--lessonmd-tip-background);
+  border-color: var(--lessonmd-tip-border);
+}
+.item .exercise details.exercise-solution .details-content { border-top-color: var(--lessonmd-tip-border); }
+.item .exercise details.exercise-solution summary { color: var(--lessonmd-tip-text); }
+
+.item kbd {
+  background-color: var(--lessonmd-surface);
+  border: 1px solid var(--lessonmd-surface-border);
+  border-bottom-width: 2px;
+  border-radius: 4px;
+  color: var(--lessonmd-text);
+  font-family: Monaco, Andale Mono, Courier New, monospace;
+  font-size: 12px;
+  padding: 1px 5px;
+  white-space: nowrap;
+}
+
+.item kbd.keys { background: none; border: none; padding: 0; }
+
+.item .steps {
+  list-style: none;
+  padding-left: 0;
+}
+
+.item .steps > .step {
+  margin-top: 0;
+  margin-bottom: 1.5em;
+  padding-left: 2.5em;
+  position: relative;
+}
+
+.item .step-heading {
+  font-weight: 600;
+  margin-bottom: .5em;
+  min-height: 1.75em;
+}
+
+.item .step-number {
+  background-color: var(--lessonmd-accent);
+  border-radius: 50%;
+  color: var(--lessonmd-accent-text);
+  display: inline-block;
+  font-size: .9em;
+  height: 1.75em;
+  left: 0;
+  line-height: 1.75em;
+  position: absolute;
+  text-align: center;
+  text-decoration: none;
+  top: 0;
+  width: 1.75em;
+}
+
+.item .step:target .step-number { box-shadow: 0 0 0 3px var(--lessonmd-highlight); }
+
+.item .quiz fieldset {
+  border: 1px solid var(--lessonmd-border);
+  border-radius: 4px;
+  margin: 1em 0;
+  padding: 1em;
+}
+
+.item .quiz legend {
+  font-weight: 600;
+  padding: 0 0.5em;
+}
+
+.item .quiz-choices {
+  list-style: none;
+  padding-left: 0;
+}
+
+.item .quiz-choice {
+  margin-top: .5em;
+}
+
+.item .quiz-choice label > p { display: inline; }
+
+.item .quiz-choice.correct label { color: var(--lessonmd-tip-text); font-weight: 600; }
+.item .quiz-choice.incorrect label { color: var(--lessonmd-warning-text); text-decoration: line-through; }
+
+.item .quiz-explanation {
+  border-left: .25em solid var(--lessonmd-quote-border);
+  color: var(--lessonmd-muted);
+  margin: .5em 0 0 1.5em;
+  padding: 0 1em;
+}
+
+.item .quiz-check {
+  cursor: pointer;
+  padding: 0.5em 1em;
+}
+
+.item .quiz-feedback { font-weight: 600; margin-top: .5em; }
+.item .quiz-feedback.correct { color: var(--lessonmd-tip-border); }
+.item .quiz-feedback.incorrect { color: var(--lessonmd-warning-border); }
+
+.item .embed { margin-bottom: 16px; }
+.item .embed iframe, .item .embed video { width: 100%; aspect-ratio: 16 / 9; border: 0; background-color: #000; }
+.item .embed-iframe iframe { background-color: transparent; }
+.item .embed audio { width: 100%; }
+
+@media print {
+` + printVariables(theme, colors) + `
+.item .tabs-nav, .item .tabs-static > .tab-label, .item .hljs-copy, .item .quiz-check, .item .quiz-feedback { display: none; }
+.item .tab-panel, .item .tabs-static > .tab-panel { display: block; }
+.item .tab-panel + .tab-panel { border-top: 1px solid var(--lessonmd-border); }
+
+.item pre, .item pre > code, .item pre.tree > code, .item pre.diff > code {
+  overflow: visible;
+  overflow-wrap: anywhere;
+  white-space: pre-wrap;
+}
+
+.item pre, .item table, .item figure, .item .notice, .item .exercise, .item .quiz { break-inside: avoid; }
+.item h1, .item h2, .item h3, .item h4, .item h5, .item h6 { break-after: avoid; }
+
+.item a[href^="http"]::after { content: " (" attr(href) ")"; color: var(--lessonmd-muted); font-size: .85em; }
+}
 `
 	style = strings.ReplaceAll(style, ".item", "."+class)
-	return style
+	return style, nil
 }
 
-func (c *converter) addMermaidJS() string {
-	return "<script>" + c.GenerateMermaidJS() + "</script>\n"
+// GenerateMermaidJS returns the script that renders Mermaid diagrams in the browser.
+func (c *converter) GenerateMermaidJS() string {
+	js, _ := c.GenerateThemedMermaidJS("")
+	return js
 }
 
-func (c *converter) GenerateMermaidJS() string {
+// GenerateThemedMermaidJS returns the script that renders Mermaid diagrams
+// in the browser with a Mermaid theme, like "forest". "" uses Mermaid's default.
+func (c *converter) GenerateThemedMermaidJS(theme string) (string, error) {
+	if err := checkMermaidTheme(theme); err != nil {
+		return "", err
+	}
+
+	config := "{startOnLoad: true}"
+	if theme != "" {
+		config = "{startOnLoad: true, theme: '" + theme + "'}"
+	}
 
 	return `
 function loadMermaid() {
+  // pass a Content Security Policy nonce on to the script we add
+  const nonce = document.currentScript ? document.currentScript.nonce : '';
   const m = document.createElement('script');
+  m.nonce = nonce;
   m.src = 'https://cdn.jsdelivr.net/npm/mermaid/dist/mermaid.min.js';
   m.async = false;
   m.addEventListener('load', function() {
     try {
-      mermaid.initialize({startOnLoad: true});
+      mermaid.initialize(` + config + `);
     } catch (error) {
       console.error(error);
     }
@@ -386,19 +704,30 @@ function loadMermaid() {
 }
 
 loadMermaid();
-`
-
+`, nil
 }
-func (c *converter) addHighlightJS(class string) string {
-	return "<script>" + c.GenerateHighlightJS(class) + "</script>\n"
+
+// checkMermaidTheme returns an error if the theme isn't a plain name,
+// since it's passed to the Mermaid CLI and written into the script.
+func checkMermaidTheme(theme string) error {
+	for _, r := range theme {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("invalid Mermaid theme %q", theme)
+		}
+	}
+	return nil
 }
 
 func (c *converter) GenerateHighlightJS(class string) string {
 
 	out := `
 async function loadHighlightJS() {
+  // pass a Content Security Policy nonce on to the scripts and styles we add
+  const nonce = document.currentScript ? document.currentScript.nonce : '';
+
   await new Promise((resolve, reject) => {
     const highlightScript = document.createElement("script");
+    highlightScript.nonce = nonce;
     highlightScript.src = 'https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.7.0/highlight.min.js';
     highlightScript.onload = resolve;
     highlightScript.onerror = reject;
@@ -407,6 +736,7 @@ async function loadHighlightJS() {
 
   await new Promise((resolve, reject) => {
     const golangScript = document.createElement("script");
+    golangScript.nonce = nonce;
     golangScript.src = 'https:////cdnjs.cloudflare.com/ajax/libs/highlight.js/11.7.0/languages/go.min.js';
     golangScript.onload = resolve;
     golangScript.onerror = reject;
@@ -415,6 +745,7 @@ async function loadHighlightJS() {
 
   await new Promise((resolve, reject) => {
     const css = document.createElement('link')
+    css.nonce = nonce;
     css.setAttribute('rel', 'stylesheet');
     css.setAttribute('href', 'https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.7.0/styles/default.min.css');
     document.body.appendChild(css);
@@ -424,7 +755,11 @@ async function loadHighlightJS() {
 
   try {
     document.querySelectorAll('.item pre code').forEach(el => {
-      hljs.highlightElement(el);
+      if (el.classList.contains('diff') || el.classList.contains('callouts')) {
+        highlightLines(el);
+      } else {
+        hljs.highlightElement(el);
+      }
     })
     addButtons();
   } catch (error) {
@@ -432,6 +767,43 @@ async function loadHighlightJS() {
   }
 };
 
+// highlightLines highlights code that has a span for each line, like diffs and
+// code with callouts, without losing the spans or the callout badges in them.
+function highlightLines(el) {
+  const lang = Array.from(el.classList).find(c => c.startsWith('language-'));
+  if (lang && hljs.getLanguage(lang.substring(9))) {
+    const lines = el.querySelectorAll(':scope > span');
+    const result = hljs.highlight(el.textContent, {language: lang.substring(9), ignoreIllegals: true});
+    splitHighlightedLines(result.value).forEach((line, i) => {
+      if (!lines[i]) return;
+      const badges = lines[i].querySelectorAll('.callout');
+      lines[i].innerHTML = line.replace(/\n$/, '');
+      badges.forEach(badge => lines[i].appendChild(badge));
+      lines[i].appendChild(document.createTextNode('\n'));
+    });
+  }
+  el.classList.add('hljs');
+}
+
+// splitHighlightedLines splits highlighted HTML into lines, closing the spans
+// that are still open at the end of a line and reopening them on the next.
+function splitHighlightedLines(html) {
+  const lines = [];
+  const open = [];
+  let current = '';
+  html.split(/(<span[^>]*>|<\/span>|\n)/).forEach(part => {
+    if (part === '\n') {
+      lines.push(current + '</span>'.repeat(open.length) + '\n');
+      current = open.join('');
+      return;
+    }
+    if (part.startsWith('<span')) open.push(part);
+    if (part === '</span>') open.pop();
+    current += part;
+  });
+  return lines;
+}
+
 function addButtons() {
   var snippets = document.getElementsByClassName('hljs');
   var numberOfSnippets = snippets.length;
@@ -443,7 +815,15 @@ function addButtons() {
 
     b.addEventListener("click", function () {
       this.innerText = 'Copying..';
-      code = this.nextSibling.innerText;
+      var el = this.nextSibling;
+      // Copy a diff as the code after the change, without removed lines or markers.
+      if (el.classList.contains('diff')) {
+        el = el.cloneNode(true);
+        el.querySelectorAll('.diff-del, .diff-hunk, .diff-header').forEach(line => line.remove());
+        code = el.textContent;
+      } else {
+        code = el.innerText;
+      }
       navigator.clipboard.writeText(code);
       this.innerText = 'Copied!';
       var that = this;
@@ -462,56 +842,91 @@ loadHighlightJS();
 
 }
 
-func (c *converter) addTabsJS(class string) string {
-	return "<script>" + c.GenerateTabsJS(class) + "</script>\n"
-}
-
 func (c *converter) GenerateTabsJS(class string) string {
 	out := `
+// The learner's tab choices, most recent first, shared by every lesson on the site
+const tabStorageKey = 'lessonmd-tabs';
+
+function loadTabChoices() {
+    try {
+        const choices = JSON.parse(localStorage.getItem(tabStorageKey));
+        return Array.isArray(choices) ? choices : [];
+    } catch (e) {
+        return []; // storage is blocked, or the value isn't ours
+    }
+}
+
+function saveTabChoice(tabName) {
+    const choices = loadTabChoices().filter(name => name !== tabName);
+    choices.unshift(tabName);
+    try {
+        localStorage.setItem(tabStorageKey, JSON.stringify(choices.slice(0, 10)));
+    } catch (e) {
+        // storage is blocked, so the choice lasts until the page is reloaded
+    }
+}
+
+// linkedTab returns the tab named by ?tab=name or #tab=name in the URL
+function linkedTab() {
+    const params = new URLSearchParams(window.location.search);
+    if (params.has('tab')) return params.get('tab').toLowerCase();
+    const hash = window.location.hash.match(/^#tab=(.+)$/);
+    return hash ? decodeURIComponent(hash[1]).toLowerCase() : null;
+}
+
+// selectTab shows the named tab in every tab group that has one
+function selectTab(tabName) {
+    if (!/^[a-z0-9-]+$/.test(tabName)) return;
+
+    // Find all tabs with the same data-tab-name across all tab groups
+    const allMatchingButtons = document.querySelectorAll('.item .tab-button[data-tab-name="' + tabName + '"]');
+    const allMatchingPanels = document.querySelectorAll('.item .tab-panels > .tab-panel[data-tab-name="' + tabName + '"]');
+    
+    // Deactivate all tabs in all groups that contain matching tabs
+    allMatchingButtons.forEach(matchingButton => {
+        const parentTabGroup = matchingButton.closest('.tabs');
+        if (parentTabGroup) {
+            // Deactivate all tabs in this group, but not in groups nested in it
+            parentTabGroup.querySelectorAll(':scope > .tabs-nav > .tab-button').forEach(b => {
+                b.classList.remove('active');
+                b.setAttribute('aria-selected', 'false');
+            });
+            parentTabGroup.querySelectorAll(':scope > .tab-panels > .tab-panel').forEach(p => {
+                p.classList.remove('active');
+            });
+        }
+    });
+    
+    // Activate all matching tabs
+    allMatchingButtons.forEach(matchingButton => {
+        matchingButton.classList.add('active');
+        matchingButton.setAttribute('aria-selected', 'true');
+    });
+    allMatchingPanels.forEach(matchingPanel => {
+        matchingPanel.classList.add('active');
+    });
+}
+
 function initializeTabs() {
     document.querySelectorAll('.item .tabs').forEach(tabGroup => {
-        const buttons = tabGroup.querySelectorAll('.tab-button');
-        const panels = tabGroup.querySelectorAll('.tab-panel');
+        const buttons = tabGroup.querySelectorAll(':scope > .tabs-nav > .tab-button');
+        if (buttons.length === 0) return; // static and print tabs work without the script
         
         // Use event delegation for better performance
         tabGroup.addEventListener('click', e => {
             const button = e.target.closest('.tab-button');
-            if (!button || !tabGroup.contains(button)) return;
+            if (!button || button.closest('.tabs') !== tabGroup) return;
             
             const tabName = button.getAttribute('data-tab-name');
             if (!tabName) return;
             
-            // Find all tabs with the same data-tab-name across all tab groups
-            const allMatchingButtons = document.querySelectorAll('.item .tab-button[data-tab-name="' + tabName + '"]');
-            const allMatchingPanels = document.querySelectorAll('.item .tab-panel[data-tab-name="' + tabName + '"]');
-            
-            // Deactivate all tabs in all groups that contain matching tabs
-            allMatchingButtons.forEach(matchingButton => {
-                const parentTabGroup = matchingButton.closest('.tabs');
-                if (parentTabGroup) {
-                    // Deactivate all tabs in this group
-                    parentTabGroup.querySelectorAll('.tab-button').forEach(b => {
-                        b.classList.remove('active');
-                        b.setAttribute('aria-selected', 'false');
-                    });
-                    parentTabGroup.querySelectorAll('.tab-panel').forEach(p => {
-                        p.classList.remove('active');
-                    });
-                }
-            });
-            
-            // Activate all matching tabs
-            allMatchingButtons.forEach(matchingButton => {
-                matchingButton.classList.add('active');
-                matchingButton.setAttribute('aria-selected', 'true');
-            });
-            allMatchingPanels.forEach(matchingPanel => {
-                matchingPanel.classList.add('active');
-            });
+            selectTab(tabName);
+            saveTabChoice(tabName);
         });
         
         // Keyboard navigation
         tabGroup.addEventListener('keydown', (e) => {
+            if (e.target.closest('.tabs') !== tabGroup) return; // a nested group handles its own keys
             if (e.key === 'ArrowLeft' || e.key === 'ArrowRight') {
                 const activeIndex = Array.from(buttons).findIndex(b => b.classList.contains('active'));
                 let newIndex;
@@ -528,6 +943,12 @@ function initializeTabs() {
             }
         });
     });
+
+    // Restore the learner's choices, oldest first so the most recent wins,
+    // then show the tab the URL links to
+    loadTabChoices().slice().reverse().forEach(selectTab);
+    const linked = linkedTab();
+    if (linked) selectTab(linked);
 }
 
 // Initialize when DOM ready
@@ -540,3 +961,104 @@ if (document.readyState === 'loading') {
 	out = strings.ReplaceAll(out, ".item", "."+class)
 	return out
 }
+
+// GenerateQuizJS returns the script that checks quiz answers in the browser.
+func (c *converter) GenerateQuizJS(class string) string {
+	out := `
+function initializeQuizzes() {
+    document.querySelectorAll('.item .quiz').forEach(quiz => {
+        const button = quiz.querySelector('.quiz-check');
+        const feedback = quiz.querySelector('.quiz-feedback');
+        if (!button) return;
+
+        button.hidden = false;
+        button.addEventListener('click', () => {
+            let correct = true;
+
+            quiz.querySelectorAll('.quiz-choice').forEach(choice => {
+                const input = choice.querySelector('input');
+                const explanation = choice.querySelector('.quiz-explanation');
+                const shouldBeChecked = input.getAttribute('data-correct') === 'true';
+
+                if (input.checked !== shouldBeChecked) correct = false;
+
+                choice.classList.remove('correct', 'incorrect');
+                if (input.checked) {
+                    choice.classList.add(shouldBeChecked ? 'correct' : 'incorrect');
+                }
+                if (explanation) explanation.hidden = !input.checked;
+            });
+
+            feedback.classList.remove('correct', 'incorrect');
+            feedback.classList.add(correct ? 'correct' : 'incorrect');
+            feedback.textContent = correct ? 'Correct!' : 'Not quite. Try again.';
+        });
+    });
+}
+
+// Initialize when DOM ready
+if (document.readyState === 'loading') {
+    document.addEventListener('DOMContentLoaded', initializeQuizzes);
+} else {
+    initializeQuizzes();
+}
+`
+	out = strings.ReplaceAll(out, ".item", "."+class)
+	return out
+}
+
+// GenerateKeysJS returns the script that shows the Mod key as Cmd on macOS and Ctrl elsewhere.
+func (c *converter) GenerateKeysJS(class string) string {
+	out := `
+function initializeKeys() {
+    const platform = (navigator.userAgentData && navigator.userAgentData.platform) || navigator.platform || '';
+    const isMac = /mac|iphone|ipad|ipod/i.test(platform);
+
+    document.querySelectorAll('.item kbd.key-mod').forEach(key => {
+        key.textContent = key.getAttribute(isMac ? 'data-key-mac' : 'data-key-other');
+    });
+}
+
+// Initialize when DOM ready
+if (document.readyState === 'loading') {
+    document.addEventListener('DOMContentLoaded', initializeKeys);
+} else {
+    initializeKeys();
+}
+`
+	out = strings.ReplaceAll(out, ".item", "."+class)
+	return out
+}
+
+// GenerateAsciinemaJS returns the script that loads asciinema-player from a CDN
+// and replaces each embedded terminal recording with a player.
+func (c *converter) GenerateAsciinemaJS(class string) string {
+	out := `
+function loadAsciinema() {
+  // pass a Content Security Policy nonce on to the script and styles we add
+  const nonce = document.currentScript ? document.currentScript.nonce : '';
+  const css = document.createElement('link');
+  css.nonce = nonce;
+  css.rel = 'stylesheet';
+  css.href = 'https://cdn.jsdelivr.net/npm/asciinema-player@3/dist/bundle/asciinema-player.css';
+  document.head.appendChild(css);
+
+  const a = document.createElement('script');
+  a.nonce = nonce;
+  a.src = 'https://cdn.jsdelivr.net/npm/asciinema-player@3/dist/bundle/asciinema-player.min.js';
+  a.async = false;
+  a.addEventListener('load', function() {
+    document.querySelectorAll('.item .asciinema-player[data-cast]').forEach(el => {
+      const src = el.getAttribute('data-cast');
+      el.textContent = '';
+      AsciinemaPlayer.create(src, el);
+    });
+  });
+  document.body.appendChild(a);
+}
+
+loadAsciinema();
+`
+	out = strings.ReplaceAll(out, ".item", "."+class)
+	return out
+}
@@ -1,9 +1,17 @@
 package lessonmd
 
 import (
+	"crypto/sha512"
+	"encoding/base64"
+	"lessonmd/extensions/tabs"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/yuin/goldmark/text"
 )
 
 func TestConvert(t *testing.T) {
@@ -495,3 +503,1724 @@ Content 2
 		t.Errorf("Expected output to contain tabs JavaScript function")
 	}
 }
+
+func TestQuiz(t *testing.T) {
+	str := `[quiz What does ls do?
+- [ ] Removes files
+  > No, that's rm.
+- [x] Lists files
+]`
+	input := []byte(str)
+	expected := `<div class="quiz" id="quiz-1">
+<fieldset>
+<legend class="quiz-question">What does ls do?</legend>
+<ul class="quiz-choices">
+<li class="quiz-choice">
+<input type="radio" name="quiz-1" id="quiz-1-choice-1" value="1" data-correct="false" aria-describedby="quiz-1-explanation-1">
+<label for="quiz-1-choice-1">Removes files</label>
+<div class="quiz-explanation" id="quiz-1-explanation-1" hidden>
+<p>No, that's rm.</p>
+</div>
+</li>
+<li class="quiz-choice">
+<input type="radio" name="quiz-1" id="quiz-1-choice-2" value="2" data-correct="true">
+<label for="quiz-1-choice-2">Lists files</label>
+</li>
+</ul>
+<button type="button" class="quiz-check" hidden>Check answer</button>
+<div class="quiz-feedback" aria-live="polite"></div>
+</fieldset>
+</div>
+`
+
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		AddStyleTag:      false,
+		AddHighlightJS:   false,
+		UseSVGforMermaid: false,
+		AddMermaidJS:     false,
+		AddTabsJS:        false,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, output)
+	}
+}
+
+func TestQuizMultipleAnswers(t *testing.T) {
+	str := `[quiz Which of these are shells?
+- [x] bash
+- [x] zsh
+- [ ] vim
+]`
+	input := []byte(str)
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		AddQuizJS:    true,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if !strings.Contains(output, `<input type="checkbox" name="quiz-1" id="quiz-1-choice-1"`) {
+		t.Errorf("Expected checkboxes for a question with several answers but it was %q", output)
+	}
+
+	if !strings.Contains(output, "initializeQuizzes") {
+		t.Errorf("Expected output to contain quiz JavaScript function")
+	}
+}
+
+func TestQuizMarkdownQuestion(t *testing.T) {
+	str := `[quiz What does ` + "`ls -a`" + ` do, *exactly*?
+- [ ] Removes files
+- [x] Lists **all** files
+]
+
+- [ ] Not a quiz`
+	input := []byte(str)
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		Extensions:   map[string]bool{"tasklist": false},
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<legend class=\"quiz-question\">What does <code>ls -a</code> do, <em>exactly</em>?</legend>",
+		"<input type=\"radio\" name=\"quiz-1\" id=\"quiz-1-choice-2\" value=\"2\" data-correct=\"true\">\n<label for=\"quiz-1-choice-2\">Lists <strong>all</strong> files</label>",
+		"<li>[ ] Not a quiz</li>",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, output)
+		}
+	}
+}
+
+func TestQuizWithCodeBlock(t *testing.T) {
+	str := "[quiz Which line ends a quiz?\n- [x] This one:\n  ```\n  ]\n  ```\n- [ ] This one\n]\n\nAfter the quiz."
+	input := []byte(str)
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<pre><code>]\n</code></pre>",
+		"<label for=\"quiz-1-choice-2\">This one</label>",
+		"</fieldset>\n</div>\n<p>After the quiz.</p>",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, output)
+		}
+	}
+}
+
+func TestQuizAnswerKey(t *testing.T) {
+	str := `[quiz What does ls do?
+- [ ] Removes files
+  > No, that's rm.
+- [x] Lists files
+]`
+	input := []byte(str)
+
+	key, err := Converter.QuizAnswerKey(input, ConverterOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(key) != 1 {
+		t.Fatalf("Expected 1 question in the answer key but got %d", len(key))
+	}
+
+	q := key[0]
+	if q.ID != "quiz-1" || q.Question != "What does ls do?" || q.Multiple {
+		t.Errorf("Unexpected question in answer key: %+v", q)
+	}
+
+	if len(q.Correct) != 1 || q.Correct[0] != 2 {
+		t.Errorf("Expected choice 2 to be the correct answer but got %v", q.Correct)
+	}
+
+	if q.Choices[0].Text != "Removes files" || q.Choices[0].Explanation != "No, that's rm." {
+		t.Errorf("Unexpected first choice in answer key: %+v", q.Choices[0])
+	}
+}
+
+func TestExercise(t *testing.T) {
+	str := `[exercise List files
+Show the files.
+
+[hint
+Use ls.
+]
+
+[hint Flags
+Try -a.
+]
+
+[solution
+ls -a
+]
+]`
+	input := []byte(str)
+	expected := `<div class="exercise" id="exercise-1">
+  <div class="exercise-heading">Exercise: List files</div>
+  <div class="exercise-body">
+<p>Show the files.</p>
+<details class="exercise-hint"><summary>Hint 1</summary>
+<div class="details-content">
+<p>Use ls.</p>
+<details class="exercise-hint"><summary>Hint 2: Flags</summary>
+<div class="details-content">
+<p>Try -a.</p>
+</div>
+</details>
+</div>
+</details>
+<details class="exercise-solution"><summary>Solution</summary>
+<div class="details-content">
+<p>ls -a</p>
+</div>
+</details>
+  </div>
+</div>
+`
+
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		AddStyleTag:      false,
+		AddHighlightJS:   false,
+		UseSVGforMermaid: false,
+		AddMermaidJS:     false,
+		AddTabsJS:        false,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if output != expected {
+		t.Errorf("Expected the output to be %q but it was %q", expected, output)
+	}
+}
+
+func TestExerciseHideSolutions(t *testing.T) {
+	str := `[exercise List files
+Show the files.
+
+[solution
+The answer is ls -a.
+]
+]`
+	input := []byte(str)
+
+	o := ConverterOptions{
+		Wrap:          false,
+		WrapperClass:  "item",
+		HideSolutions: true,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if strings.Contains(output, "exercise-solution") || strings.Contains(output, "ls -a") {
+		t.Errorf("Expected the solution to be removed but it was %q", output)
+	}
+}
+
+func TestExerciseHideNestedSolutions(t *testing.T) {
+	tests := map[string]string{
+		"details": `[exercise List files
+Show the files.
+
+[solution
+[details Why
+secret
+]
+]
+]
+
+After the exercise.`,
+		"fence": "[exercise Make a list\nWrite a JSON array.\n\n[solution\n```json\n[\n  \"secret\"\n]\n```\n]\n]\n\nAfter the exercise.",
+		"links": "[exercise Read the docs\n[the docs](https://example.com) explain it, and\n[details](https://example.com/details) has more.\n\n[solution\nsecret\n]\n]\n\nAfter the exercise.",
+	}
+
+	o := ConverterOptions{
+		Wrap:          false,
+		WrapperClass:  "item",
+		HideSolutions: true,
+	}
+
+	for name, str := range tests {
+		output, _ := Converter.Run([]byte(str), o)
+
+		if strings.Contains(output, "secret") || strings.Contains(output, "]") {
+			t.Errorf("%s: Expected the solution to be removed but it was %q", name, output)
+		}
+
+		if !strings.Contains(output, "<p>After the exercise.</p>") {
+			t.Errorf("%s: Expected the exercise to end at its own ] but it was %q", name, output)
+		}
+	}
+}
+
+func TestSteps(t *testing.T) {
+	str := "[steps\nFirst, read this.\n\n## Step: Create the project\n\n```command\nnpm init -y\n```\n\nStep: Install\nRun the installer.\n]"
+	input := []byte(str)
+	expected := `<p>First, read this.</p>
+<ol class="steps">
+<li class="step" id="step-1" value="1">
+<div class="step-heading"><a class="step-number" href="#step-1" aria-label="Step 1">1</a> Create the project</div>
+<pre><code class="language-bash command">npm init -y
+</code></pre>
+</li>
+<li class="step" id="step-2" value="2">
+<div class="step-heading"><a class="step-number" href="#step-2" aria-label="Step 2">2</a> Install</div>
+<p>Run the installer.</p>
+</li>
+</ol>
+`
+
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		AddStyleTag:      false,
+		AddHighlightJS:   false,
+		UseSVGforMermaid: false,
+		AddMermaidJS:     false,
+		AddTabsJS:        false,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if output != expected {
+		t.Errorf("Expected the output to be %q but it was %q", expected, output)
+	}
+}
+
+func TestStepsContinue(t *testing.T) {
+	str := "[steps\nStep: One\n]\n\nSome prose.\n\n[steps continue\nStep: Two\n]\n\n[steps\nStep: Again\n]\n\n[steps continue\nStep: And again\n]"
+	input := []byte(str)
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if !strings.Contains(output, `<ol class="steps" start="2">
+<li class="step" id="step-2" value="2">`) {
+		t.Errorf("Expected the second block to continue numbering but it was %q", output)
+	}
+
+	if !strings.Contains(output, `<li class="step" id="steps-3-step-1" value="1">`) {
+		t.Errorf("Expected the third block to restart numbering with unique ids but it was %q", output)
+	}
+
+	if !strings.Contains(output, `<li class="step" id="steps-3-step-2" value="2">`) || strings.Count(output, `id="step-2"`) != 1 {
+		t.Errorf("Expected the fourth block to continue the third block's ids but it was %q", output)
+	}
+}
+
+func TestStepsWithNestedBlocks(t *testing.T) {
+	str := "[steps\n## Step: One\n\n```json\n[\n  1\n]\n```\n\n[details More\nhidden\n]\n\nStill step one.\n\n## Step: Two\n\nDone.\n]\n\nAfter."
+	input := []byte(str)
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<code class=\"language-json\">[\n  1\n]\n</code></pre>",
+		"</details>\n<p>Still step one.</p>\n</li>\n<li class=\"step\" id=\"step-2\" value=\"2\">",
+		"<p>Done.</p>\n</li>\n</ol>\n<p>After.</p>",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, output)
+		}
+	}
+}
+
+func TestKeyboardShortcuts(t *testing.T) {
+	input := []byte("Press [[Ctrl+Shift+P]] or ++ctrl+alt+del++ in C++.")
+	expected := `<p>Press <kbd class="keys"><kbd>Ctrl</kbd>+<kbd>Shift</kbd>+<kbd>P</kbd></kbd> or <kbd class="keys"><kbd>Ctrl</kbd>+<kbd>Alt</kbd>+<kbd>Del</kbd></kbd> in C++.</p>`
+
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		AddStyleTag:      false,
+		AddHighlightJS:   false,
+		UseSVGforMermaid: false,
+		AddMermaidJS:     false,
+		AddTabsJS:        false,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, output)
+	}
+}
+
+func TestKeyboardShortcutsMod(t *testing.T) {
+	input := []byte("Save with [[Mod+S]].")
+	expected := `<kbd class="key-mod" data-key-mac="Cmd" data-key-other="Ctrl">Ctrl/Cmd</kbd>+<kbd>S</kbd>`
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		AddKeysJS:    true,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, output)
+	}
+
+	if !strings.Contains(output, "initializeKeys") {
+		t.Errorf("Expected output to contain keys JavaScript function")
+	}
+}
+
+func TestFootnotesAndDefinitionLists(t *testing.T) {
+	input := []byte("Term\n: Definition\n\nSee the note[^1].\n\n[^1]: A footnote.\n")
+
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		AddStyleTag:      false,
+		AddHighlightJS:   false,
+		UseSVGforMermaid: false,
+		AddMermaidJS:     false,
+		AddTabsJS:        false,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if !strings.Contains(output, "<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>") {
+		t.Errorf("Expected output to contain a definition list but it was %q", output)
+	}
+
+	if !strings.Contains(output, `<div class="footnotes" role="doc-endnotes">`) {
+		t.Errorf("Expected output to contain footnotes but it was %q", output)
+	}
+}
+
+func TestExtensionsToggle(t *testing.T) {
+	input := []byte("It's \"quoted\".\n\n:::note Notice\ntest\n:::\n")
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		Extensions:   map[string]bool{"typographer": true, "notices": false},
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(output, "&ldquo;quoted&rdquo;") {
+		t.Errorf("Expected typographer to convert quotes but it was %q", output)
+	}
+
+	if strings.Contains(output, `<div class="notice note">`) {
+		t.Errorf("Expected notices to be turned off but it was %q", output)
+	}
+}
+
+func TestUnknownExtension(t *testing.T) {
+	o := ConverterOptions{
+		WrapperClass: "item",
+		Extensions:   map[string]bool{"bogus": true},
+	}
+
+	_, err := Converter.Run([]byte("Hello"), o)
+
+	if err == nil || !strings.Contains(err.Error(), `unknown extension "bogus"`) {
+		t.Errorf("Expected an unknown extension error but got %v", err)
+	}
+}
+
+func TestFigures(t *testing.T) {
+	input := []byte("![Arch](arch.png)\nFigure: The *architecture* {#fig:arch}\n\n![Other](other.png \"Another figure\")\n")
+	expected := `<figure class="figure figure-image" id="fig:arch">
+<img src="arch.png" alt="Arch">
+<figcaption><span class="figure-label">Figure 1:</span> The <em>architecture</em></figcaption>
+</figure>
+<figure class="figure figure-image" id="figure-2">
+<img src="other.png" alt="Other" title="Another figure">
+<figcaption><span class="figure-label">Figure 2:</span> Another figure</figcaption>
+</figure>
+`
+
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		AddStyleTag:      false,
+		AddHighlightJS:   false,
+		UseSVGforMermaid: false,
+		AddMermaidJS:     false,
+		AddTabsJS:        false,
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if output != expected {
+		t.Errorf("Expected the output to be %q but it was %q", expected, output)
+	}
+}
+
+func TestTableAndListingCaptions(t *testing.T) {
+	input := []byte("Table: Sales {#tbl:sales}\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n```go\nx := 1\n```\nListing: Declaring a variable\n\nAn ![inline](x.png \"title\") image.\n")
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, _ := Converter.Run(input, o)
+
+	if !strings.Contains(output, "<figure class=\"figure figure-table\" id=\"tbl:sales\">\n<figcaption><span class=\"figure-label\">Table 1:</span> Sales</figcaption>\n<table>") {
+		t.Errorf("Expected a captioned table but it was %q", output)
+	}
+
+	if !strings.Contains(output, "</code></pre>\n<figcaption><span class=\"figure-label\">Listing 1:</span> Declaring a variable</figcaption>\n</figure>") {
+		t.Errorf("Expected a captioned listing but it was %q", output)
+	}
+
+	if !strings.Contains(output, `<p>An <img src="x.png" alt="inline" title="title"> image.</p>`) {
+		t.Errorf("Expected inline images to stay in the paragraph but it was %q", output)
+	}
+}
+
+func TestCrossReferences(t *testing.T) {
+	input := []byte("# Lesson\n\n## Intro\n\nSee [@sec:middleware], [@sec:middleware title], and [@fig:arch].\n\n## Server\n\n### Middleware\n\n![Arch](arch.png)\nFigure: The architecture {#fig:arch}\n")
+	expected := `<p>See <a class="xref" href="#middleware">Middleware</a>, <a class="xref" href="#middleware">Middleware</a>, and <a class="xref" href="#fig:arch">Figure 1</a>.</p>`
+
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		AddStyleTag:      false,
+		AddHighlightJS:   false,
+		UseSVGforMermaid: false,
+		AddMermaidJS:     false,
+		AddTabsJS:        false,
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, output)
+	}
+
+	// Numbered headings are referred to by their numbers.
+	o.NumberHeadings = true
+	output, err = Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected = `<p>See <a class="xref" href="#middleware">Section 2.1</a>, <a class="xref" href="#middleware">Middleware</a>, and <a class="xref" href="#fig:arch">Figure 1</a>.</p>`
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, output)
+	}
+}
+
+func TestCrossReferenceMissingTarget(t *testing.T) {
+	input := []byte("# Lesson\n\nSome text.\n\nSee [@sec:nowhere].\n")
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	_, err := Converter.Run(input, o)
+
+	expected := `line 5: unknown cross-reference target "sec:nowhere"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
+
+func TestIDPrefix(t *testing.T) {
+	input := []byte("# Lesson\n\n## Setup\n\nSee [@sec:usage], [how to use it](#usage), [the quiz](#quiz-1), or [the Linux tab](#tab=linux).\n\n=== \"Linux\"\n    apt\n\n## Usage\n\n[quiz Pick one\n- [x] A\n- [ ] B\n]\n")
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		IDPrefix:     "l1-",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<h2 id="l1-setup">Setup</h2>`,
+		`<a class="xref" href="#l1-usage">Usage</a>`,
+		`<div class="tabs" id="l1-tabs-1">`,
+		`aria-controls="l1-tab-panel-1-1" id="l1-tab-1-1"`,
+		`<div class="quiz" id="l1-quiz-1">`,
+		`<label for="l1-quiz-1-choice-1">A</label>`,
+		`<a href="#l1-usage">how to use it</a>`,
+		`<a href="#l1-quiz-1">the quiz</a>`,
+		`<a href="#tab=linux">the Linux tab</a>`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, output)
+		}
+	}
+}
+
+func TestNumberHeadings(t *testing.T) {
+	input := []byte("# Lesson\n\n## Setup\n\n### Install\n\n## Usage\n")
+	expected := `<h1 id="lesson">Lesson</h1>
+<h2 id="setup"><span class="heading-number">1</span> Setup</h2>
+<h3 id="install"><span class="heading-number">1.1</span> Install</h3>
+<h2 id="usage"><span class="heading-number">2</span> Usage</h2>
+`
+
+	o := ConverterOptions{
+		Wrap:           false,
+		WrapperClass:   "item",
+		NumberHeadings: true,
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestNumberHeadingsWithSteps(t *testing.T) {
+	input := []byte("# Lesson\n\n## Setup\n\n[steps\n## Step: Install\nRun the installer.\n]\n\n## Usage\n")
+
+	o := ConverterOptions{
+		Wrap:           false,
+		WrapperClass:   "item",
+		NumberHeadings: true,
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<a class=\"step-number\" href=\"#step-1\" aria-label=\"Step 1\">1</a> Install</div>",
+		"<h2 id=\"usage\"><span class=\"heading-number\">2</span> Usage</h2>",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, output)
+		}
+	}
+}
+
+func TestEmbedYouTube(t *testing.T) {
+	input := []byte("@[youtube](https://www.youtube.com/watch?v=dQw4w9WgXcQ \"Intro talk\" start=30)\n")
+	expected := `<div class="embed embed-youtube">
+<iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?start=30" title="Intro talk" loading="lazy" referrerpolicy="strict-origin-when-cross-origin" allow="encrypted-media; fullscreen; picture-in-picture" allowfullscreen></iframe>
+</div>
+`
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestEmbedVideoWithCaptions(t *testing.T) {
+	input := []byte("@[video](media/demo.mp4 captions=media/demo.vtt)\n\n@[iframe](javascript:alert(1))\n\n@[video](media/demo.mp4 poster=javascript:alert(1))\n\n@[audio](media/demo.mp3 captions=javascript:alert(1))\n")
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<video controls preload="metadata" src="media/demo.mp4" aria-label="Video">`,
+		`<track kind="captions" src="media/demo.vtt" srclang="en" label="Captions" default>`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, output)
+		}
+	}
+
+	if strings.Contains(output, "<iframe") || strings.Contains(output, "poster=\"javascript:") || strings.Contains(output, "src=\"javascript:") || strings.Count(output, "<video") != 1 {
+		t.Errorf("Expected javascript: URLs not to be embedded but it was %q", output)
+	}
+}
+
+func TestTreeBlock(t *testing.T) {
+	input := []byte("```tree\nmyapp/\n  cmd/\n    main.go   # entry point\n  **go.mod**\n```\n")
+	expected := `<pre class="tree"><code class="nohighlight hljs tree"><span class="tree-folder">myapp/</span>
+├── <span class="tree-folder">cmd/</span>
+│   └── <span class="tree-file">main.go</span>  <span class="tree-comment"># entry point</span>
+└── <span class="tree-file tree-highlight">go.mod</span>
+</code></pre>
+`
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestTreeBlockFolderWithoutSlash(t *testing.T) {
+	input := []byte("```tree\nsrc\n\tapp.js\n\tlib\n\t\tutil.js\n```\n")
+	expected := "<span class=\"tree-folder\">src</span>\n├── <span class=\"tree-file\">app.js</span>\n└── <span class=\"tree-folder\">lib</span>\n    └── <span class=\"tree-file\">util.js</span>\n"
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, output)
+	}
+}
+
+func TestDiffBlock(t *testing.T) {
+	input := []byte("```go diff=true\n func main() {\n-\tfmt.Println(1)\n+\tfmt.Println(2)\n }\n```\n")
+	expected := `<pre class="diff"><code class="language-go diff"><span class="diff-line diff-context">func main() {
+</span><span class="diff-line diff-del">	fmt.Println(1)
+</span><span class="diff-line diff-add">	fmt.Println(2)
+</span><span class="diff-line diff-context">}
+</span></code></pre>
+`
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestDiffBlockSplit(t *testing.T) {
+	input := []byte("```diff split\n-old\n+new\n```\n")
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<pre class="diff diff-before"><code class="diff"><span class="diff-line diff-del">old` + "\n</span></code></pre>",
+		`<pre class="diff diff-after"><code class="diff"><span class="diff-line diff-add">new` + "\n</span></code></pre>",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, output)
+		}
+	}
+}
+
+func TestDiffBlockFileHeaders(t *testing.T) {
+	input := []byte("```diff\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n--- old\n+++ new\n```\n")
+	expected := `<pre class="diff"><code class="diff"><span class="diff-line diff-header">--- a/main.go
+</span><span class="diff-line diff-header">+++ b/main.go
+</span><span class="diff-line diff-hunk">@@ -1,2 +1,2 @@
+</span><span class="diff-line diff-del">-- old
+</span><span class="diff-line diff-add">++ new
+</span></code></pre>
+`
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestCodeCallouts(t *testing.T) {
+	input := []byte("```go\nimport \"fmt\" // <1>\nfmt.Println() # <2>\n```\n\n1. Imports fmt.\n2. Prints.\n")
+	expected := `<pre class="callouts"><code class="language-go callouts"><span class="code-line">import &#34;fmt&#34;<a class="callout" id="callout-1-1-ref" href="#callout-1-1" data-callout="1" aria-label="Callout 1"></a>
+</span><span class="code-line">fmt.Println()<a class="callout" id="callout-1-2-ref" href="#callout-1-2" data-callout="2" aria-label="Callout 2"></a>
+</span></code></pre>
+<ol class="callout-list">
+<li id="callout-1-1"><a class="callout" href="#callout-1-1-ref" data-callout="1" aria-label="Back to callout 1"></a> Imports fmt.</li>
+<li id="callout-1-2"><a class="callout" href="#callout-1-2-ref" data-callout="2" aria-label="Back to callout 2"></a> Prints.</li>
+</ol>
+`
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestCodeWithoutCallouts(t *testing.T) {
+	input := []byte("```html\n<ul>\n  <li>1</li>\n</ul>\n```\n")
+	expected := "<pre><code class=\"language-html\">&lt;ul&gt;\n  &lt;li&gt;1&lt;/li&gt;\n&lt;/ul&gt;\n</code></pre>\n"
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestSafeMode(t *testing.T) {
+	input := []byte("# Title {onclick=\"steal()\" .big}\n\n[bad](JavaScript:alert(1)) and [good](https://example.com)\n\nPress <kbd>Ctrl</kbd> <span onclick=\"x\">now</span><sup onmouseover=\"y\">2</sup>\n\n<script>alert(1)</script>\n")
+	expected := `<h1 class="big" id="title">Title</h1>
+<p>bad and <a href="https://example.com">good</a></p>
+<p>Press <kbd>Ctrl</kbd> now<sup>2</sup></p>
+`
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		Safe:         true,
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestSafeModeAllowlist(t *testing.T) {
+	input := []byte("<span data-term=\"x\" style=\"color:red\">term</span> and <kbd>K</kbd>\n")
+	expected := "<p><span data-term=\"x\">term</span> and K</p>\n"
+
+	o := ConverterOptions{
+		Wrap:              false,
+		WrapperClass:      "item",
+		Safe:              true,
+		AllowedTags:       []string{"span"},
+		AllowedAttributes: []string{"id", "data-*"},
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if output != expected {
+		t.Errorf("Expected %q but got %q", expected, output)
+	}
+}
+
+func TestSafeModeExtensions(t *testing.T) {
+	dot := fakeDiagramCommand(t)
+	mmdc := fakeMermaidCLI(t)
+	input := []byte("@[iframe](https://example.com/)\n\n@[video](media/demo.mp4 poster=\"javascript:alert(1)\")\n\n```dot\ndigraph { a [URL=\"javascript:alert(1)\"] }\n```\n\n```mermaid\ngraph TD;\n  A-->B;\n```\n")
+
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		Safe:             true,
+		DiagramCommands:  map[string]string{"dot": "'" + dot + "'"},
+		UseSVGforMermaid: true,
+		MermaidCLI:       mmdc,
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, unexpected := range []string{"<iframe", "<video", "poster=\"", "<svg"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Expected the output not to include %q but it was %q", unexpected, output)
+		}
+	}
+	for _, expected := range []string{"<code class=\"language-dot\">", "<div class=\"mermaid\">graph TD;"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, output)
+		}
+	}
+	for _, command := range []string{dot, mmdc} {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(command), "runs")); err == nil {
+			t.Errorf("Expected %s not to run in safe mode", filepath.Base(command))
+		}
+	}
+}
+
+func TestCSPNonce(t *testing.T) {
+	input := []byte("# Hello")
+
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		AddStyleTag:  true,
+		AddTabsJS:    true,
+		CSPNonce:     "r4nd0m",
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Count(output, `<style nonce="r4nd0m">`) != 1 || strings.Count(output, `<script nonce="r4nd0m">`) != 1 {
+		t.Errorf("Expected a nonce on the style and script tags but it was %q", output)
+	}
+	if strings.Contains(output, "<script>") || strings.Contains(output, "<style>") {
+		t.Errorf("Expected every tag to have a nonce but it was %q", output)
+	}
+}
+
+func TestExternalAssets(t *testing.T) {
+	input := []byte("# Hello")
+	dir := filepath.Join(t.TempDir(), "assets")
+
+	o := ConverterOptions{
+		Wrap:           false,
+		WrapperClass:   "item",
+		AddStyleTag:    true,
+		AddTabsJS:      true,
+		ExternalAssets: dir,
+	}
+
+	output, err := Converter.Run(input, o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := Converter.WriteAssets(o); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	js, err := os.ReadFile(filepath.Join(dir, "lessonmd-tabs.js"))
+	if err != nil {
+		t.Fatalf("Expected the tabs script to be written: %v", err)
+	}
+	if string(js) != Converter.GenerateTabsJS("item") {
+		t.Errorf("Expected the tabs script file to contain the tabs script")
+	}
+
+	sum := sha512.Sum384(js)
+	expected := `<script src="` + dir + `/lessonmd-tabs.js" integrity="sha384-` + base64.StdEncoding.EncodeToString(sum[:]) + `" crossorigin="anonymous"></script>`
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, output)
+	}
+	if !strings.Contains(output, `<link rel="stylesheet" href="`+dir+`/lessonmd.css" integrity="sha384-`) {
+		t.Errorf("Expected the output to link to the stylesheet but it was %q", output)
+	}
+}
+
+func TestThemedCSS(t *testing.T) {
+	css, err := Converter.GenerateThemedCSS("lesson", "auto", map[string]string{"link": "#7b2cbf"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		".lesson {\n  --lessonmd-text: #333;",
+		"@media (prefers-color-scheme: dark) {\n.lesson {\n  --lessonmd-text: #e6edf3;",
+		".lesson {\n  --lessonmd-link: #7b2cbf;\n}",
+		".lesson a { color: var(--lessonmd-link); }",
+	} {
+		if !strings.Contains(css, expected) {
+			t.Errorf("Expected the stylesheet to include %q but it was %q", expected, css)
+		}
+	}
+
+	if !strings.HasPrefix(Converter.GenerateCSS("item"), "\n.item {\n  --lessonmd-text: #333;") {
+		t.Errorf("Expected the default stylesheet to use the light theme")
+	}
+}
+
+func TestUnknownTheme(t *testing.T) {
+	o := ConverterOptions{
+		WrapperClass: "item",
+		AddStyleTag:  true,
+		Theme:        "solarized",
+	}
+
+	_, err := Converter.Run([]byte("# Hello"), o)
+	if err == nil || !strings.Contains(err.Error(), `unknown theme "solarized"`) {
+		t.Errorf("Expected an unknown theme error but got %v", err)
+	}
+
+	o.Theme = "dark"
+	o.ThemeColors = map[string]string{"link": "red; } body { display: none"}
+	_, err = Converter.Run([]byte("# Hello"), o)
+	if err == nil || !strings.Contains(err.Error(), `invalid value`) {
+		t.Errorf("Expected an invalid color error but got %v", err)
+	}
+}
+
+func TestPrintMode(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		Print:        true,
+	}
+
+	markdown := "## Install\n\n=== \"Linux\"\n    Run apt.\n\n=== \"macOS\"\n    Run brew.\n\n[details Why?\nBecause.\n]\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<div class=\"tabs tabs-print\" id=\"tabs-1\">",
+		"<h3 class=\"tab-title\">Linux</h3>\n<p>Run apt.</p>",
+		"<h3 class=\"tab-title\">macOS</h3>\n<p>Run brew.</p>",
+		"<details open><summary>Why?</summary>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+	if strings.Contains(html, "tab-button") {
+		t.Errorf("Expected no tab buttons in print mode but the output was %q", html)
+	}
+}
+
+func TestPrintCSS(t *testing.T) {
+	css, err := Converter.GenerateThemedCSS("item", "dark", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"@media print {\n.item {\n  --lessonmd-text: #333;",
+		".item .tab-panel, .item .tabs-static > .tab-panel { display: block; }",
+		"white-space: pre-wrap;",
+	} {
+		if !strings.Contains(css, expected) {
+			t.Errorf("Expected the stylesheet to include %q but it was %q", expected, css)
+		}
+	}
+}
+
+func TestStaticTabs(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		StaticTabs:   true,
+	}
+
+	markdown := "=== \"Linux\"\n    Run apt.\n\n=== \"macOS\"\n    Run brew.\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<div class=\"tabs tabs-static\" id=\"tabs-1\">",
+		"<input type=\"radio\" class=\"tab-input\" name=\"tabs-1\" id=\"tab-1-1\" aria-controls=\"tab-panel-1-1\" checked>\n  <label class=\"tab-label\" for=\"tab-1-1\" data-tab-name=\"linux\">Linux</label>",
+		"<input type=\"radio\" class=\"tab-input\" name=\"tabs-1\" id=\"tab-1-2\" aria-controls=\"tab-panel-1-2\">",
+		"<div class=\"tab-panel\" id=\"tab-panel-1-2\" data-tab-name=\"macos\">\n<p>Run brew.</p>\n  </div>\n</div>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+}
+
+func TestStaticTabsFrontmatter(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	html, err := Converter.Run([]byte("---\ntabs: static\n---\n=== \"Linux\"\n    Run apt.\n"), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(html, "tabs-static") {
+		t.Errorf("Expected static tabs but the output was %q", html)
+	}
+
+	o.StaticTabs = true
+	html, err = Converter.Run([]byte("---\ntabs: script\n---\n=== \"Linux\"\n    Run apt.\n"), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(html, "tabs-static") || !strings.Contains(html, "tab-button") {
+		t.Errorf("Expected scripted tabs but the output was %q", html)
+	}
+}
+
+func TestDefaultTab(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	markdown := "=== \"Linux\"\n    Run apt.\n\n=== \"macOS\" default\n    Run brew.\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<button class=\"tab-button\" role=\"tab\" aria-selected=\"false\" aria-controls=\"tab-panel-1-1\" id=\"tab-1-1\" data-tab-name=\"linux\">Linux</button>",
+		"<button class=\"tab-button active\" role=\"tab\" aria-selected=\"true\" aria-controls=\"tab-panel-1-2\" id=\"tab-1-2\" data-tab-name=\"macos\">macOS</button>",
+		"<div class=\"tab-panel active\" role=\"tabpanel\" aria-labelledby=\"tab-1-2\" id=\"tab-panel-1-2\" data-tab-name=\"macos\">",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+
+	o.StaticTabs = true
+	html, err = Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "id=\"tab-1-2\" aria-controls=\"tab-panel-1-2\" checked>"
+	if !strings.Contains(html, expected) || strings.Count(html, " checked") != 1 {
+		t.Errorf("Expected the output to include %q but it was %q", expected, html)
+	}
+}
+
+func TestTabsJSRemembersChoices(t *testing.T) {
+	js := Converter.GenerateTabsJS("lesson")
+
+	for _, expected := range []string{
+		"localStorage.setItem(tabStorageKey",
+		"params.get('tab')",
+		"document.querySelectorAll('.lesson .tabs')",
+	} {
+		if !strings.Contains(js, expected) {
+			t.Errorf("Expected the script to include %q but it was %q", expected, js)
+		}
+	}
+}
+
+func TestTabGroups(t *testing.T) {
+	md, err := Converter.newMarkdown(ConverterOptions{WrapperClass: "item"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	markdown := []byte("## Install\n\n=== \"macOS\"\n    Run brew.\n\n=== \"Linux\" default\n    Run apt.\n\nBetween.\n\n=== \"Windows\"\n    Run winget.\n")
+	doc := md.Parser().Parse(text.NewReader(markdown))
+
+	var groups []*tabs.TabGroup
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		if g, ok := n.(*tabs.TabGroup); ok {
+			groups = append(groups, g)
+		}
+	}
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 tab groups but there were %d", len(groups))
+	}
+
+	g := groups[0]
+	if g.ID != "tabs-1" || g.Title != "Install" || g.SyncKey != "linux,macos" || g.ChildCount() != 2 {
+		t.Errorf("Expected tabs-1 titled Install with key linux,macos and 2 tabs but it was %s titled %q with key %q and %d tabs", g.ID, g.Title, g.SyncKey, g.ChildCount())
+	}
+	linux := g.LastChild().(*tabs.Tab)
+	if linux.ID != "tab-1-2" || linux.PanelID != "tab-panel-1-2" || !linux.Selected {
+		t.Errorf("Expected the Linux tab to be tab-1-2 and selected but it was %s, selected %v", linux.ID, linux.Selected)
+	}
+	if g := groups[1]; g.ID != "tabs-2" || g.SyncKey != "windows" || !g.FirstChild().(*tabs.Tab).Selected {
+		t.Errorf("Expected tabs-2 with key windows and its first tab selected but it was %s with key %q", g.ID, g.SyncKey)
+	}
+}
+
+func TestTabGroupsWithIDPrefix(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+		IDPrefix:     "lesson1-",
+	}
+
+	html, err := Converter.Run([]byte("=== \"Linux\"\n    Run apt.\n"), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<div class=\"tabs\" id=\"lesson1-tabs-1\">",
+		"aria-controls=\"lesson1-tab-panel-1-1\" id=\"lesson1-tab-1-1\"",
+		"aria-labelledby=\"lesson1-tab-1-1\" id=\"lesson1-tab-panel-1-1\"",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+}
+
+func TestNestedTabs(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	markdown := "=== \"Linux\"\n    === \"Debian\"\n        Run apt.\n\n    === \"Fedora\"\n        Run dnf.\n\n=== \"macOS\"\n    Run brew.\n\nAfter.\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"data-tab-name=\"linux\">\n<div class=\"tabs\" id=\"tabs-2\">",
+		"data-tab-name=\"debian\">\n<p>Run apt.</p>\n    </div>",
+		"data-tab-name=\"fedora\">\n<p>Run dnf.</p>\n    </div>\n  </div>\n</div>\n    </div>",
+		"data-tab-name=\"macos\">\n<p>Run brew.</p>\n    </div>\n  </div>\n</div>\n<p>After.</p>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+
+	// Selecting an outer tab mustn't touch the buttons and panels of the
+	// groups nested in it.
+	js := Converter.GenerateTabsJS("item")
+	for _, expected := range []string{
+		"parentTabGroup.querySelectorAll(':scope > .tabs-nav > .tab-button')",
+		"parentTabGroup.querySelectorAll(':scope > .tab-panels > .tab-panel')",
+		"tabGroup.querySelectorAll(':scope > .tabs-nav > .tab-button')",
+		"button.closest('.tabs') !== tabGroup",
+		"e.target.closest('.tabs') !== tabGroup",
+	} {
+		if !strings.Contains(js, expected) {
+			t.Errorf("Expected the script to include %q but it was %q", expected, js)
+		}
+	}
+}
+
+func TestTabsWithTabCharacters(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	markdown := "=== \"Linux\"\n\tRun apt.\n\n\t\tindented code\n\n\t=== \"Debian\"\n\t\tRun apt-get.\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"data-tab-name=\"linux\">\n<p>Run apt.</p>\n<pre><code>indented code\n</code></pre>",
+		"data-tab-name=\"debian\">\n<p>Run apt-get.</p>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+}
+
+// fakeMermaidCLI writes a stand-in for mmdc that records each run in the
+// runs file next to it and fails for diagrams containing "fail".
+func fakeMermaidCLI(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in Mermaid CLI is a shell script")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --input) input="$2"; shift ;;
+    --output) output="$2"; shift ;;
+    --theme) theme="$2"; shift ;;
+  esac
+  shift
+done
+echo run >> "$(dirname "$0")/runs"
+if grep -q fail "$input"; then
+  echo "Parse error on line 1" >&2
+  exit 1
+fi
+printf '<svg data-theme="%s"><style>svg{}</style></svg>' "$theme" > "$output"
+`
+	cli := filepath.Join(dir, "mmdc")
+	if err := os.WriteFile(cli, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func TestMermaidSVGCache(t *testing.T) {
+	cli := fakeMermaidCLI(t)
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		UseSVGforMermaid: true,
+		MermaidCLI:       cli,
+		MermaidTheme:     "forest",
+		DiagramCache:     filepath.Join(t.TempDir(), "cache"),
+	}
+
+	markdown := []byte("```mermaid\ngraph TD;\n  A-->B;\n```\n\n```mermaid\ngraph TD;\n  A-->B;\n```\n")
+	for i := 0; i < 2; i++ {
+		html, err := Converter.Run(markdown, o)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "<div class=\"mermaid\"><svg data-theme=\"forest\"><style>svg{}</style></svg></div>\n"
+		if strings.Count(html, expected) != 2 {
+			t.Errorf("Expected the output to include %q twice but it was %q", expected, html)
+		}
+	}
+
+	runs, _ := os.ReadFile(filepath.Join(filepath.Dir(cli), "runs"))
+	if strings.Count(string(runs), "run") != 1 {
+		t.Errorf("Expected the Mermaid CLI to run once but it ran %d times", strings.Count(string(runs), "run"))
+	}
+
+	_, err := Converter.Run([]byte("# Diagrams\n\n```mermaid\nfail\n```\n"), o)
+	if err == nil || !strings.Contains(err.Error(), "mermaid diagram at line 3") || !strings.Contains(err.Error(), "Parse error on line 1") {
+		t.Errorf("Expected an error for the diagram at line 3 but it was %v", err)
+	}
+
+	// another CLI, like a new version of mmdc, renders the diagram again
+	o.MermaidCLI = fakeMermaidCLI(t)
+	if _, err := Converter.Run(markdown, o); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runs, _ = os.ReadFile(filepath.Join(filepath.Dir(o.MermaidCLI), "runs"))
+	if strings.Count(string(runs), "run") != 1 {
+		t.Errorf("Expected the other Mermaid CLI to run once but it ran %d times", strings.Count(string(runs), "run"))
+	}
+}
+
+func TestMermaidTheme(t *testing.T) {
+	js, err := Converter.GenerateThemedMermaidJS("dark")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "mermaid.initialize({startOnLoad: true, theme: 'dark'});"
+	if !strings.Contains(js, expected) {
+		t.Errorf("Expected the script to include %q but it was %q", expected, js)
+	}
+
+	o := ConverterOptions{
+		WrapperClass: "item",
+		AddMermaidJS: true,
+		MermaidTheme: "dark'});alert(1",
+	}
+	_, err = Converter.Run([]byte("# Hello"), o)
+	if err == nil || !strings.Contains(err.Error(), "invalid Mermaid theme") {
+		t.Errorf("Expected an invalid theme error but it was %v", err)
+	}
+}
+
+// fakeDiagramCommand writes a stand-in for a tool like Graphviz that reads
+// the diagram on standard input, records each run in the runs file next to
+// it, and fails for diagrams containing "fail".
+func fakeDiagramCommand(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in diagram command is a shell script")
+	}
+	// a space in the path checks that the command can be quoted
+	dir := filepath.Join(t.TempDir(), "diagram tools")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := `#!/bin/sh
+src=$(cat)
+echo run >> "$(dirname "$0")/runs"
+case "$src" in
+  ""|*fail*) echo "syntax error in line 1" >&2; exit 1 ;;
+esac
+printf '<?xml version="1.0"?>\n<svg><style>text{}</style><text>%s</text></svg>\n' "$1"
+`
+	command := filepath.Join(dir, "fake-dot")
+	if err := os.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return command
+}
+
+func TestDiagramCommands(t *testing.T) {
+	command := fakeDiagramCommand(t)
+	o := ConverterOptions{
+		Wrap:            false,
+		WrapperClass:    "item",
+		DiagramCommands: map[string]string{"dot": "\"" + command + "\" -Tsvg"},
+		DiagramCache:    filepath.Join(t.TempDir(), "cache"),
+	}
+
+	markdown := []byte("```dot\ndigraph { a -> b }\n```\n\n```go\nfmt.Println()\n```\n")
+	for i := 0; i < 2; i++ {
+		html, err := Converter.Run(markdown, o)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "<div class=\"diagram diagram-dot\"><svg><style>text{}</style><text>-Tsvg</text></svg>\n</div>\n"
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+		expected = "<code class=\"language-go\">"
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+
+	runs, _ := os.ReadFile(filepath.Join(filepath.Dir(command), "runs"))
+	if strings.Count(string(runs), "run") != 1 {
+		t.Errorf("Expected the diagram command to run once but it ran %d times", strings.Count(string(runs), "run"))
+	}
+
+	// a changed command, like a new version of Graphviz, renders the
+	// diagram again
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(command, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Converter.Run(markdown, o); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runs, _ = os.ReadFile(filepath.Join(filepath.Dir(command), "runs"))
+	if strings.Count(string(runs), "run") != 2 {
+		t.Errorf("Expected the changed diagram command to run again but it ran %d times", strings.Count(string(runs), "run"))
+	}
+
+	_, err := Converter.Run([]byte("# Graphs\n\nSome text.\n\n```dot\nfail\n```\n"), o)
+	if err == nil || !strings.Contains(err.Error(), "dot diagram at line 5") || !strings.Contains(err.Error(), "syntax error in line 1") {
+		t.Errorf("Expected an error for the diagram at line 5 but it was %v", err)
+	}
+
+	_, err = Converter.Run([]byte("# Graphs\n\n```dot\n```\n"), o)
+	if err == nil || !strings.Contains(err.Error(), "dot diagram at line 3") {
+		t.Errorf("Expected an error for the empty diagram at line 3 but it was %v", err)
+	}
+}
+
+func TestDiagramAssets(t *testing.T) {
+	command := fakeDiagramCommand(t)
+	assets := filepath.Join(t.TempDir(), "diagrams")
+	o := ConverterOptions{
+		Wrap:            false,
+		WrapperClass:    "item",
+		DiagramCommands: map[string]string{"plantuml": "'" + command + "'"},
+		DiagramAssets:   assets,
+	}
+
+	html, err := Converter.Run([]byte("```plantuml\n@startuml\nA -> B\n@enduml\n```\n"), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(assets, "plantuml-*.svg"))
+	if len(files) != 1 {
+		t.Fatalf("Expected one SVG file but there were %v", files)
+	}
+	expected := "<div class=\"diagram diagram-plantuml\"><img src=\"" + filepath.ToSlash(files[0]) + "\" alt=\"plantuml diagram\"></div>\n"
+	if html != expected {
+		t.Errorf("Expected the output to be %q but it was %q", expected, html)
+	}
+}
+
+func TestDiagramCSPNonce(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:             false,
+		WrapperClass:     "item",
+		UseSVGforMermaid: true,
+		MermaidCLI:       fakeMermaidCLI(t),
+		DiagramCommands:  map[string]string{"dot": "\"" + fakeDiagramCommand(t) + "\""},
+		CSPNonce:         "abc123",
+	}
+
+	html, err := Converter.Run([]byte("```mermaid\ngraph TD;\n```\n\n```dot\ndigraph { a -> b }\n```\n"), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"<svg data-theme=\"\"><style nonce=\"abc123\">svg{}</style></svg>",
+		"<svg><style nonce=\"abc123\">text{}</style>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+}
+
+func TestASCIIDiagrams(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	markdown := "```bob \"Request flow\"\n+---------+     +--------+\n| Browser |---->| Server |\n+---------+     +--------+\n```\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectations := []string{
+		"<figure class=\"ascii-diagram\">\n<svg ",
+		"role=\"img\" aria-label=\"Request flow\"><title>Request flow</title>",
+		"<line x1=\"4\" y1=\"8\" x2=\"84\" y2=\"8\"/>",
+		"<polygon points=\"120,20 128,24 120,28\"/>",
+		"<text x=\"16\" y=\"28\">Browser</text>",
+		"<details class=\"ascii-diagram-source\"><summary>Diagram source</summary><pre><code class=\"nohighlight\">+---------+     +--------+\n| Browser |----&gt;| Server |\n",
+	}
+	for _, expected := range expectations {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+}
+
+func TestASCIIDiagramText(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	// hyphens, periods, and slashes in labels aren't lines
+	markdown := "```ascii\n.----------.\n| e-mail   |\n| a/b v2.0 |\n'----------'\n```\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectations := []string{
+		"aria-label=\"Diagram: e-mail, a/b v2.0\"",
+		"<path d=\"M8 8 Q4 8 4 16\"/>",
+		"<text x=\"16\" y=\"28\">e-mail</text>",
+		"<text x=\"16\" y=\"44\">a/b v2.0</text>",
+	}
+	for _, expected := range expectations {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+}
+
+func TestCSVTables(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	markdown := "```csv max-rows=2 decimals=,0,2 thousands=true caption=\"Sales by region\"\nRegion,Units,Revenue\nNorth,1200,34567.5\n\"South, East\",85,\"1,234.5\"\nWest,7,12\n```\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectations := []string{
+		"<div class=\"csv-table\">\n<table>\n<caption>Sales by region</caption>\n<thead>\n<tr>\n<th>Region</th>\n<th style=\"text-align:right\">Units</th>\n",
+		"<td>North</td>\n<td style=\"text-align:right\">1,200</td>\n<td style=\"text-align:right\">34,567.50</td>\n",
+		"<td>South, East</td>\n<td style=\"text-align:right\">85</td>\n<td style=\"text-align:right\">1,234.50</td>\n",
+		"</table>\n<p class=\"csv-table-note\">Showing 2 of 3 rows.</p>\n</div>\n",
+	}
+	for _, expected := range expectations {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+	if strings.Contains(html, "West") {
+		t.Errorf("Expected the output to stop after two rows but it was %q", html)
+	}
+
+	_, err = Converter.Run([]byte("# Data\n\n```csv max-rows=all\na,b\n```\n"), o)
+	if err == nil || !strings.Contains(err.Error(), "csv table at line 3: invalid max-rows \"all\"") {
+		t.Errorf("Expected an error for the table at line 3 but it was %v", err)
+	}
+}
+
+func TestCSVTableInclude(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "scores.tsv")
+	if err := os.WriteFile(file, []byte("Name\tScore\nAda\t98\nGrace\t100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	markdown := "{{ csvtable \"" + filepath.ToSlash(file) + "\" align=center }}\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "<tr>\n<td style=\"text-align:center\">Grace</td>\n<td style=\"text-align:right\">100</td>\n</tr>\n"
+	if !strings.Contains(html, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, html)
+	}
+
+	// untrusted Markdown can't read files
+	o.Safe = true
+	html, err = Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(html, "Grace") {
+		t.Errorf("Expected safe mode not to include the file but it was %q", html)
+	}
+
+	o.Safe = false
+	_, err = Converter.Run([]byte("Scores:\n\n{{ csvtable \"missing.csv\" }}\n"), o)
+	if err == nil || !strings.Contains(err.Error(), "csv table at line 3: failed to read missing.csv") {
+		t.Errorf("Expected an error for the table at line 3 but it was %v", err)
+	}
+}
+
+func TestCharts(t *testing.T) {
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	markdown := "```chart\ntype: bar\ntitle: Revenue by region\nlabels: [North, South]\nseries:\n  - name: \"2023\"\n    values: [120, 80]\n  - name: \"2024\"\n    values: [150, 95]\n```\n\n" +
+		"```chart\n{\"type\": \"pie\", \"labels\": [\"Yes\", \"No\"], \"series\": [{\"values\": [3, 1]}]}\n```\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectations := []string{
+		"<figure class=\"chart chart-bar\">\n<svg ",
+		"role=\"img\" aria-label=\"Revenue by region\"><title>Revenue by region</title><desc>Bar chart of 2023, 2024 for North, South.</desc>",
+		"<g class=\"chart-color-2\" fill=\"#bc4c00\" stroke=\"#bc4c00\"><rect ",
+		"<title>2024, South: 95</title>",
+		"<details class=\"chart-data\"><summary>Chart data</summary>\n<table>\n",
+		"<th scope=\"row\">North</th>\n<td style=\"text-align:right\">120</td>\n<td style=\"text-align:right\">150</td>\n",
+		"<figure class=\"chart chart-pie\">",
+		"<path class=\"chart-color-1\" fill=\"#0969da\" stroke=\"#0969da\" d=\"M212 180 L212 16 A164 164 0 1 1 48 180 Z\"><title>Yes: 3 (75%)</title></path>",
+		"<text x=\"442\" y=\"64\">No (25%)</text>",
+	}
+	for _, expected := range expectations {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the output to include %q but it was %q", expected, html)
+		}
+	}
+
+	// the stylesheet colors the series with the theme
+	o.Theme = "dark"
+	css, err := Converter.GenerateThemedCSS(o.WrapperClass, o.Theme, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"--lessonmd-chart-1: #4493f8;", ".item .chart-color-1 { fill: var(--lessonmd-chart-1); stroke: var(--lessonmd-chart-1); }"} {
+		if !strings.Contains(css, expected) {
+			t.Errorf("Expected the stylesheet to include %q but it was %q", expected, css)
+		}
+	}
+}
+
+func TestChartData(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "signups.csv")
+	if err := os.WriteFile(file, []byte("Month,Signups\nJan,10\nFeb,25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o := ConverterOptions{
+		Wrap:         false,
+		WrapperClass: "item",
+	}
+
+	markdown := "# Growth\n\n```chart\ntype: line\ncsv: " + filepath.ToSlash(file) + "\n```\n"
+	html, err := Converter.Run([]byte(markdown), o)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "<circle cx=\"482\" cy=\"16\" r=\"3\"><title>Signups, Feb: 25</title></circle>"
+	if !strings.Contains(html, expected) {
+		t.Errorf("Expected the output to include %q but it was %q", expected, html)
+	}
+
+	// untrusted Markdown can't read files
+	o.Safe = true
+	_, err = Converter.Run([]byte(markdown), o)
+	if err == nil || !strings.Contains(err.Error(), "chart at line 3: charts can't read files in safe mode") {
+		t.Errorf("Expected a safe mode error for the chart at line 3 but it was %v", err)
+	}
+
+	o.Safe = false
+	_, err = Converter.Run([]byte("```chart\ntype: bar\nlabels: [a, b]\nseries:\n  - values: [1]\n```\n"), o)
+	if err == nil || !strings.Contains(err.Error(), "chart at line 1: series \"Series 1\" has 1 values for 2 labels") {
+		t.Errorf("Expected an error for the chart at line 1 but it was %v", err)
+	}
+}
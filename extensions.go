@@ -0,0 +1,169 @@
+package lessonmd
+
+import (
+	"fmt"
+	"lessonmd/extensions/asciidiagrams"
+	"lessonmd/extensions/callouts"
+	"lessonmd/extensions/charts"
+	"lessonmd/extensions/commandblocks"
+	"lessonmd/extensions/csvtables"
+	"lessonmd/extensions/details"
+	"lessonmd/extensions/diagrams"
+	"lessonmd/extensions/diffblocks"
+	"lessonmd/extensions/embed"
+	"lessonmd/extensions/exercise"
+	"lessonmd/extensions/figures"
+	"lessonmd/extensions/inlinehighlight"
+	"lessonmd/extensions/kbd"
+	"lessonmd/extensions/notices"
+	"lessonmd/extensions/outputblocks"
+	"lessonmd/extensions/quiz"
+	"lessonmd/extensions/steps"
+	"lessonmd/extensions/tabs"
+	"lessonmd/extensions/treeblocks"
+	"lessonmd/extensions/xref"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"go.abhg.dev/goldmark/mermaid"
+)
+
+// namedExtension is an extension that can be turned on or off by name
+// with ConverterOptions.Extensions or the extensions config key.
+type namedExtension struct {
+	name    string
+	enabled bool // on by default
+	build   func(o ConverterOptions) goldmark.Extender
+}
+
+// availableExtensions lists every extension in the order it's added to goldmark.
+var availableExtensions = []namedExtension{
+	// builtin
+	{"table", true, func(o ConverterOptions) goldmark.Extender { return extension.Table }},
+	{"strikethrough", true, func(o ConverterOptions) goldmark.Extender { return extension.Strikethrough }},
+	{"linkify", true, func(o ConverterOptions) goldmark.Extender { return extension.Linkify }},
+	{"tasklist", true, func(o ConverterOptions) goldmark.Extender { return extension.TaskList }},
+	{"footnote", true, func(o ConverterOptions) goldmark.Extender {
+		if o.IDPrefix != "" {
+			return extension.NewFootnote(extension.WithFootnoteIDPrefix([]byte(o.IDPrefix)))
+		}
+		return extension.Footnote
+	}},
+	{"definition-list", true, func(o ConverterOptions) goldmark.Extender { return extension.DefinitionList }},
+	// Typographer rewrites quotes and dashes in existing lessons, so it's opt-in.
+	{"typographer", false, func(o ConverterOptions) goldmark.Extender { return extension.Typographer }},
+
+	// imported
+	{"mermaid", true, func(o ConverterOptions) goldmark.Extender {
+		// The CLI's SVG is inlined as it is, so untrusted diagrams are left
+		// for Mermaid's script to draw with its strict security level.
+		if o.UseSVGforMermaid && !o.Safe {
+			return &diagrams.MermaidExtender{
+				CLI:    o.MermaidCLI,
+				Args:   o.MermaidArgs,
+				Theme:  o.MermaidTheme,
+				Cache:  &diagrams.Cache{Dir: o.DiagramCache},
+				Assets: o.DiagramAssets,
+				Nonce:  o.CSPNonce,
+			}
+		}
+		return &mermaid.Extender{NoScript: true, RenderMode: mermaid.RenderModeClient}
+	}},
+
+	// custom
+	{"diagrams", true, func(o ConverterOptions) goldmark.Extender {
+		// Untrusted diagrams could read files or add links and scripts
+		// through the tools that draw them, so they stay code blocks.
+		commands := o.DiagramCommands
+		if o.Safe {
+			commands = nil
+		}
+		return &diagrams.Extender{
+			Commands: commands,
+			Cache:    &diagrams.Cache{Dir: o.DiagramCache},
+			Assets:   o.DiagramAssets,
+			Nonce:    o.CSPNonce,
+		}
+	}},
+	{"ascii-diagrams", true, func(o ConverterOptions) goldmark.Extender { return asciidiagrams.ASCIIDiagramExtender }},
+	{"output", true, func(o ConverterOptions) goldmark.Extender { return outputblocks.OutputExtender }},
+	{"highlight", true, func(o ConverterOptions) goldmark.Extender { return inlinehighlight.InlineHighlighter }},
+	{"kbd", true, func(o ConverterOptions) goldmark.Extender { return kbd.KbdExtender }},
+	{"command", true, func(o ConverterOptions) goldmark.Extender { return commandblocks.CommandExtender }},
+	{"diff", true, func(o ConverterOptions) goldmark.Extender { return diffblocks.DiffExtender }},
+	{"callouts", true, func(o ConverterOptions) goldmark.Extender { return callouts.CalloutsExtender }},
+	{"tree", true, func(o ConverterOptions) goldmark.Extender { return treeblocks.TreeExtender }},
+	{"notices", true, func(o ConverterOptions) goldmark.Extender { return notices.AdmonitionExtender }},
+	{"details", true, func(o ConverterOptions) goldmark.Extender { return &details.Extender{Open: o.Print} }},
+	{"tabs", true, func(o ConverterOptions) goldmark.Extender {
+		return &tabs.Extender{Print: o.Print, Static: o.StaticTabs}
+	}},
+	{"quiz", true, func(o ConverterOptions) goldmark.Extender { return quiz.QuizExtender }},
+	{"exercise", true, func(o ConverterOptions) goldmark.Extender {
+		return &exercise.Extender{HideSolutions: o.HideSolutions, Open: o.Print}
+	}},
+	{"steps", true, func(o ConverterOptions) goldmark.Extender { return steps.StepsExtender }},
+	{"csv", true, func(o ConverterOptions) goldmark.Extender { return &csvtables.Extender{NoFiles: o.Safe} }},
+	{"chart", true, func(o ConverterOptions) goldmark.Extender { return &charts.Extender{NoFiles: o.Safe} }},
+	{"figures", true, func(o ConverterOptions) goldmark.Extender { return figures.FiguresExtender }},
+	{"xref", true, func(o ConverterOptions) goldmark.Extender {
+		return &xref.Extender{NumberedHeadings: o.NumberHeadings}
+	}},
+	{"embed", true, func(o ConverterOptions) goldmark.Extender { return &embed.Extender{NoIframes: o.Safe} }},
+}
+
+// ExtensionNames returns the names of every extension that can be turned on or off.
+func ExtensionNames() []string {
+	names := make([]string, 0, len(availableExtensions))
+	for _, e := range availableExtensions {
+		names = append(names, e.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// enabledExtensions returns the extensions to use, applying the
+// overrides in o.Extensions to the defaults.
+func enabledExtensions(o ConverterOptions) ([]goldmark.Extender, error) {
+	known := map[string]bool{}
+	for _, e := range availableExtensions {
+		known[e.name] = true
+	}
+	for name := range o.Extensions {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown extension %q (available: %s)", name, strings.Join(ExtensionNames(), ", "))
+		}
+	}
+
+	var extensions []goldmark.Extender
+	for _, e := range availableExtensions {
+		enabled := e.enabled
+		if v, ok := o.Extensions[e.name]; ok {
+			enabled = v
+		}
+		if enabled {
+			extensions = append(extensions, e.build(o))
+		}
+	}
+	return extensions, nil
+}
+
+// ParseExtensionList parses a comma-separated list like "typographer,-tabs"
+// into a map of extension names to enable (no prefix or "+") or disable ("-").
+func ParseExtensionList(list string) map[string]bool {
+	extensions := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case strings.HasPrefix(name, "-"):
+			extensions[name[1:]] = false
+		default:
+			extensions[strings.TrimPrefix(name, "+")] = true
+		}
+	}
+	return extensions
+}
@@ -0,0 +1,21 @@
+package asciidiagrams
+
+import "github.com/yuin/goldmark/ast"
+
+// ASCIIDiagramKind is ASCIIDiagram
+var ASCIIDiagramKind = ast.NewNodeKind("ASCIIDiagram")
+
+// ASCIIDiagram is a box-and-arrow drawing in a `bob` or `ascii` code fence.
+// Its lines are the drawing.
+type ASCIIDiagram struct {
+	ast.BaseBlock
+	Title string // From the fence, like ```bob "Network layout"; the renderer describes the drawing if it's empty
+}
+
+// Kind reports that this is an ASCIIDiagram.
+func (*ASCIIDiagram) Kind() ast.NodeKind { return ASCIIDiagramKind }
+
+// Dump dumps the contents of this block to stdout.
+func (d *ASCIIDiagram) Dump(src []byte, level int) {
+	ast.DumpHelper(d, src, level, map[string]string{"Title": d.Title}, nil)
+}
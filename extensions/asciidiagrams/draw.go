@@ -0,0 +1,329 @@
+package asciidiagrams
+
+import (
+	"fmt"
+	h "html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Every character of a drawing gets a cell this many pixels wide and tall,
+// about the size it takes up in a monospace font.
+const (
+	cellWidth  = 8
+	cellHeight = 16
+	tabWidth   = 8
+)
+
+// grid holds a drawing one rune per cell, with tabs expanded.
+type grid [][]rune
+
+func newGrid(lines []string) grid {
+	g := make(grid, 0, len(lines))
+	for _, line := range lines {
+		var cells []rune
+		for _, r := range line {
+			if r == '\t' {
+				for len(cells)%tabWidth != tabWidth-1 {
+					cells = append(cells, ' ')
+				}
+				r = ' '
+			}
+			cells = append(cells, r)
+		}
+		g = append(g, cells)
+	}
+	return g
+}
+
+// at returns the rune in the cell, or a space outside the drawing.
+func (g grid) at(row, col int) rune {
+	if row < 0 || row >= len(g) || col < 0 || col >= len(g[row]) {
+		return ' '
+	}
+	return g[row][col]
+}
+
+// sides records which sides of a cell a line leaves from.
+type sides struct {
+	left, right, up, down bool
+}
+
+func (s sides) any() bool {
+	return s.left || s.right || s.up || s.down
+}
+
+// connections returns the sides of the cell where a neighboring
+// character carries a line on.
+func (g grid) connections(row, col int) sides {
+	return sides{
+		left:  strings.ContainsRune("-+.'*<", g.at(row, col-1)),
+		right: strings.ContainsRune("-+.'*>", g.at(row, col+1)),
+		up:    strings.ContainsRune("|+.*^", g.at(row-1, col)),
+		down:  strings.ContainsRune("|+'*vV", g.at(row+1, col)),
+	}
+}
+
+// segment is a straight line from (x1, y1) to (x2, y2).
+type segment struct {
+	x1, y1, x2, y2 int
+}
+
+// mergeSegments joins horizontal and vertical segments that continue one
+// another, so a line across many cells becomes one <line>. Segments run
+// left to right and top to bottom.
+func mergeSegments(segments []segment) []segment {
+	var horizontal, vertical, other []segment
+	for _, s := range segments {
+		switch {
+		case s.y1 == s.y2:
+			horizontal = append(horizontal, s)
+		case s.x1 == s.x2:
+			vertical = append(vertical, s)
+		default:
+			other = append(other, s)
+		}
+	}
+	sort.Slice(horizontal, func(i, j int) bool {
+		a, b := horizontal[i], horizontal[j]
+		return a.y1 < b.y1 || a.y1 == b.y1 && a.x1 < b.x1
+	})
+	sort.Slice(vertical, func(i, j int) bool {
+		a, b := vertical[i], vertical[j]
+		return a.x1 < b.x1 || a.x1 == b.x1 && a.y1 < b.y1
+	})
+
+	var merged []segment
+	for _, s := range horizontal {
+		if n := len(merged); n > 0 && merged[n-1].y1 == s.y1 && s.x1 <= merged[n-1].x2 {
+			if s.x2 > merged[n-1].x2 {
+				merged[n-1].x2 = s.x2
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	start := len(merged)
+	for _, s := range vertical {
+		if n := len(merged); n > start && merged[n-1].x1 == s.x1 && s.y1 <= merged[n-1].y2 {
+			if s.y2 > merged[n-1].y2 {
+				merged[n-1].y2 = s.y2
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return append(merged, other...)
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// draw converts the drawing to an SVG. Lines, corners, and arrows become
+// shapes, and everything else is text. The SVG is titled with title, or
+// with the text in the drawing if there's no title. It's drawn in
+// currentColor, so it takes the color of the text around it.
+func draw(lines []string, title string) string {
+	g := newGrid(lines)
+
+	var segments []segment
+	var strokes, fills, text strings.Builder
+	var labels []string
+	width := 0
+	for row, cells := range g {
+		if len(cells) > width {
+			width = len(cells)
+		}
+
+		// Text runs on through single spaces, so "Web server" is one label.
+		var label []rune
+		labelCol, spaces := 0, 0
+		flush := func() {
+			if len(label) == 0 {
+				return
+			}
+			fmt.Fprintf(&text, "<text x=\"%d\" y=\"%d\">%s</text>", labelCol*cellWidth, row*cellHeight+12, h.EscapeString(string(label)))
+			labels = append(labels, string(label))
+			label = nil
+		}
+		for col, r := range cells {
+			switch {
+			case r == ' ':
+				spaces++
+				if spaces > 1 {
+					flush()
+				}
+			case g.drawCell(row, col, &segments, &strokes, &fills):
+				flush()
+			default:
+				if len(label) == 0 {
+					labelCol = col
+				} else {
+					label = append(label, []rune(strings.Repeat(" ", spaces))...)
+				}
+				label = append(label, r)
+				spaces = 0
+			}
+		}
+		flush()
+	}
+
+	if title == "" {
+		title = "Diagram"
+		if len(labels) > 0 {
+			title += ": " + strings.Join(labels, ", ")
+		}
+	}
+
+	for _, s := range mergeSegments(segments) {
+		fmt.Fprintf(&strokes, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\"/>", s.x1, s.y1, s.x2, s.y2)
+	}
+
+	var b strings.Builder
+	w, ht := width*cellWidth, len(g)*cellHeight
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 %d %d\" width=\"%d\" height=\"%d\" role=\"img\" aria-label=\"%s\">", w, ht, w, ht, h.EscapeString(title))
+	b.WriteString("<title>" + h.EscapeString(title) + "</title>")
+	if strokes.Len() > 0 {
+		b.WriteString("<g fill=\"none\" stroke=\"currentColor\" stroke-width=\"1.5\" stroke-linecap=\"round\">" + strokes.String() + "</g>")
+	}
+	if fills.Len() > 0 {
+		b.WriteString("<g fill=\"currentColor\">" + fills.String() + "</g>")
+	}
+	if text.Len() > 0 {
+		b.WriteString("<g fill=\"currentColor\" font-family=\"monospace\" font-size=\"13px\">" + text.String() + "</g>")
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// drawCell draws the character in the cell as part of a line, a corner, or
+// an arrow, and returns false if it's text instead. Straight lines go in
+// segments, to be merged, and everything else in strokes and fills. A character only
+// counts as drawing when its neighbors carry the line on, so hyphens,
+// periods, and slashes in labels stay text.
+func (g grid) drawCell(row, col int, segments *[]segment, strokes, fills *strings.Builder) bool {
+	x0, y0 := col*cellWidth, row*cellHeight
+	x1, y1 := x0+cellWidth, y0+cellHeight
+	cx, cy := x0+cellWidth/2, y0+cellHeight/2
+
+	line := func(ax, ay, bx, by int) {
+		*segments = append(*segments, segment{ax, ay, bx, by})
+	}
+	triangle := func(ax, ay, bx, by, qx, qy int) {
+		fmt.Fprintf(fills, "<polygon points=\"%d,%d %d,%d %d,%d\"/>", ax, ay, bx, by, qx, qy)
+	}
+	// spokes draws a line from the middle of the cell to each side
+	spokes := func(s sides) {
+		if s.left {
+			line(x0, cy, cx, cy)
+		}
+		if s.right {
+			line(cx, cy, x1, cy)
+		}
+		if s.up {
+			line(cx, y0, cx, cy)
+		}
+		if s.down {
+			line(cx, cy, cx, y1)
+		}
+	}
+
+	left, right := g.at(row, col-1), g.at(row, col+1)
+	up, down := g.at(row-1, col), g.at(row+1, col)
+	around := g.connections(row, col)
+
+	switch g.at(row, col) {
+	case '-':
+		if !strings.ContainsRune("-+.'*<>|", left) && !strings.ContainsRune("-+.'*<>|", right) {
+			return false
+		}
+		line(x0, cy, x1, cy)
+
+	case '_':
+		if isAlnum(left) || isAlnum(right) || !strings.ContainsRune("_|", left) && !strings.ContainsRune("_|", right) {
+			return false
+		}
+		line(x0, y1, x1, y1)
+
+	case '|':
+		if !strings.ContainsRune("|+.'^vV*", up) && !strings.ContainsRune("|+.'^vV*", down) {
+			return false
+		}
+		line(cx, y0, cx, y1)
+
+	case '+':
+		if !around.any() {
+			return false
+		}
+		spokes(around)
+
+	case '.', '\'':
+		// rounded corners: . opens downward and ' upward
+		vy, vertical := y1, around.down
+		if g.at(row, col) == '\'' {
+			vy, vertical = y0, around.up
+		}
+		if !vertical || !around.left && !around.right {
+			return false
+		}
+		if around.left {
+			fmt.Fprintf(strokes, "<path d=\"M%d %d Q%d %d %d %d\"/>", x0, cy, cx, cy, cx, vy)
+		}
+		if around.right {
+			fmt.Fprintf(strokes, "<path d=\"M%d %d Q%d %d %d %d\"/>", x1, cy, cx, cy, cx, vy)
+		}
+
+	case '*':
+		if !around.any() {
+			return false
+		}
+		spokes(around)
+		fmt.Fprintf(fills, "<circle cx=\"%d\" cy=\"%d\" r=\"3\"/>", cx, cy)
+
+	case '>':
+		if !strings.ContainsRune("-+", left) {
+			return false
+		}
+		triangle(x0, cy-4, x1, cy, x0, cy+4)
+
+	case '<':
+		if !strings.ContainsRune("-+", right) {
+			return false
+		}
+		triangle(x1, cy-4, x0, cy, x1, cy+4)
+
+	case '^':
+		if !strings.ContainsRune("|+", down) {
+			return false
+		}
+		line(cx, cy, cx, y1)
+		triangle(cx-4, cy, cx, y0, cx+4, cy)
+
+	case 'v', 'V':
+		if !strings.ContainsRune("|+", up) || isAlnum(left) || isAlnum(right) {
+			return false
+		}
+		line(cx, y0, cx, cy)
+		triangle(cx-4, cy, cx, y1, cx+4, cy)
+
+	case '/':
+		if isAlnum(left) || isAlnum(right) ||
+			!strings.ContainsRune("/+|.'_*", g.at(row-1, col+1)) && !strings.ContainsRune("/+|.'_*", g.at(row+1, col-1)) {
+			return false
+		}
+		line(x0, y1, x1, y0)
+
+	case '\\':
+		if isAlnum(left) || isAlnum(right) ||
+			!strings.ContainsRune("\\+|.'_*", g.at(row-1, col-1)) && !strings.ContainsRune("\\+|.'_*", g.at(row+1, col+1)) {
+			return false
+		}
+		line(x0, y0, x1, y1)
+
+	default:
+		return false
+	}
+	return true
+}
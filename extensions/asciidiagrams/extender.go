@@ -0,0 +1,22 @@
+package asciidiagrams
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type asciiDiagramExtender struct{}
+
+// ASCIIDiagramExtender turns code fences labeled `bob` or `ascii` into SVG drawings.
+var ASCIIDiagramExtender = &asciiDiagramExtender{}
+
+func (e *asciiDiagramExtender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&ASCIIDiagramTransformer{}, 100),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&ASCIIDiagramHTMLRenderer{}, 0),
+	))
+}
@@ -0,0 +1,44 @@
+package asciidiagrams
+
+import (
+	h "html"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// ASCIIDiagramHTMLRenderer renders ASCII diagrams.
+type ASCIIDiagramHTMLRenderer struct{}
+
+func (r *ASCIIDiagramHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ASCIIDiagramKind, r.Render)
+}
+
+// Render does the actual rendering. The drawing becomes an SVG titled
+// for screen readers, and the original text follows it in a collapsed
+// <details>, for anyone who'd rather read or copy the source.
+func (r *ASCIIDiagramHTMLRenderer) Render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ASCIIDiagram)
+
+	var lines []string
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		lines = append(lines, strings.TrimRight(string(line.Value(src)), "\r\n"))
+	}
+
+	w.WriteString("<figure class=\"ascii-diagram\">\n")
+	w.WriteString(draw(lines, n.Title))
+	w.WriteString("<details class=\"ascii-diagram-source\"><summary>Diagram source</summary>")
+	w.WriteString("<pre><code class=\"nohighlight\">")
+	for _, line := range lines {
+		w.WriteString(h.EscapeString(line) + "\n")
+	}
+	w.WriteString("</code></pre></details>\n")
+	w.WriteString("</figure>\n")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,65 @@
+package asciidiagrams
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- ASCIIDiagramTransformer
+
+// ASCIIDiagramTransformer transforms code fences labeled `bob` or `ascii`
+// into ASCIIDiagrams.
+type ASCIIDiagramTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *ASCIIDiagramTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var blocks []*ast.FencedCodeBlock
+	var titles []string
+
+	// Collect all blocks to be replaced without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+
+		cb, ok := node.(*ast.FencedCodeBlock)
+		if !ok || cb.Info == nil {
+			return ast.WalkContinue, nil
+		}
+
+		title, ok := parseInfo(string(cb.Info.Segment.Value(reader.Source())))
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		blocks = append(blocks, cb)
+		titles = append(titles, title)
+		return ast.WalkContinue, nil
+	})
+
+	// replace the old code blocks with the new ones using our type.
+	for i, cb := range blocks {
+		d := &ASCIIDiagram{Title: titles[i]}
+		d.SetLines(cb.Lines())
+
+		parent := cb.Parent()
+		if parent != nil {
+			parent.ReplaceChild(parent, cb, d)
+		}
+	}
+}
+
+// parseInfo reads the info string of a fence, like `bob` or
+// `ascii "Network layout"`, and returns the title. It returns false if
+// the fence isn't a diagram.
+func parseInfo(info string) (string, bool) {
+	language, title, _ := strings.Cut(strings.TrimSpace(info), " ")
+	if language != "bob" && language != "ascii" {
+		return "", false
+	}
+	return strings.Trim(strings.TrimSpace(title), "\""), true
+}
@@ -0,0 +1,79 @@
+package callouts
+
+import (
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// CalloutBlock is a code block with numbered callouts at the end of some lines.
+type CalloutBlock struct {
+	ast.BaseBlock
+	Language  string
+	BlockID   string // e.g. "callout-1"; each callout's id adds its number
+	CodeLines []CodeLine
+	Explained map[int]bool // Callout numbers with an explanation in the list after the block
+}
+
+// CodeLine is a line of code without its callout markers.
+type CodeLine struct {
+	Text     string
+	Callouts []int
+}
+
+// Dump implements Node.Dump.
+func (n *CalloutBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Language": n.Language,
+		"BlockID":  n.BlockID,
+	}, nil)
+}
+
+// KindCalloutBlock is a NodeKind of the CalloutBlock node.
+var KindCalloutBlock = ast.NewNodeKind("CalloutBlock")
+
+// Kind implements Node.Kind.
+func (n *CalloutBlock) Kind() ast.NodeKind {
+	return KindCalloutBlock
+}
+
+// IsRaw reports that this block is code, so it can be captioned as a listing.
+func (n *CalloutBlock) IsRaw() bool { return true }
+
+// PrefixIDs adds prefix to the ids of the callouts.
+func (n *CalloutBlock) PrefixIDs(prefix string) {
+	n.BlockID = prefix + n.BlockID
+}
+
+// CalloutRef is the badge at the start of an explanation that links back to the code.
+type CalloutRef struct {
+	ast.BaseInline
+	BlockID string
+	Number  int
+}
+
+// Dump implements Node.Dump.
+func (n *CalloutRef) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"BlockID": n.BlockID,
+		"Number":  strconv.Itoa(n.Number),
+	}, nil)
+}
+
+// KindCalloutRef is a NodeKind of the CalloutRef node.
+var KindCalloutRef = ast.NewNodeKind("CalloutRef")
+
+// Kind implements Node.Kind.
+func (n *CalloutRef) Kind() ast.NodeKind {
+	return KindCalloutRef
+}
+
+// PrefixIDs adds prefix to the ids the badge links to.
+func (n *CalloutRef) PrefixIDs(prefix string) {
+	n.BlockID = prefix + n.BlockID
+}
+
+// CalloutID returns the id of the explanation for a callout number.
+func CalloutID(blockID string, number int) string {
+	return blockID + "-" + strconv.Itoa(number)
+}
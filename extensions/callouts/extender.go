@@ -0,0 +1,25 @@
+package callouts
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type calloutsExtender struct{}
+
+// CalloutsExtender is an extension that turns markers like '// <1>' at the end
+// of code lines into numbered badges linked to the ordered list after the code.
+var CalloutsExtender = &calloutsExtender{}
+
+func (e *calloutsExtender) Extend(m goldmark.Markdown) {
+	// Runs after the command, output, diff and tree transformers
+	// so it only sees plain code blocks.
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&CalloutTransformer{}, 150),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(NewCalloutHTMLRenderer(), 0),
+	))
+}
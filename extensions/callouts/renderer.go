@@ -0,0 +1,84 @@
+package callouts
+
+import (
+	h "html"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// CalloutHTMLRenderer is a renderer.NodeRenderer implementation that
+// renders CalloutBlock and CalloutRef nodes.
+type CalloutHTMLRenderer struct {
+	html.Config
+}
+
+// NewCalloutHTMLRenderer returns a new CalloutHTMLRenderer.
+func NewCalloutHTMLRenderer(opts ...html.Option) renderer.NodeRenderer {
+	r := &CalloutHTMLRenderer{
+		Config: html.NewConfig(),
+	}
+	for _, opt := range opts {
+		opt.SetHTMLOption(&r.Config)
+	}
+	return r
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *CalloutHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindCalloutBlock, r.renderCalloutBlock)
+	reg.Register(KindCalloutRef, r.renderCalloutRef)
+}
+
+// renderCalloutBlock writes each line of code in its own span. The badges
+// are empty links numbered by the stylesheet, so they're not part of the
+// code when it's copied.
+func (r *CalloutHTMLRenderer) renderCalloutBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*CalloutBlock)
+
+	_, _ = w.WriteString("<pre class=\"callouts\"><code class=\"")
+	if n.Language != "" {
+		_, _ = w.WriteString("language-" + h.EscapeString(n.Language) + " ")
+	}
+	_, _ = w.WriteString("callouts\">")
+
+	seen := map[int]bool{}
+	for _, l := range n.CodeLines {
+		_, _ = w.WriteString("<span class=\"code-line\">" + h.EscapeString(l.Text))
+		for _, number := range l.Callouts {
+			num := strconv.Itoa(number)
+			if !n.Explained[number] {
+				_, _ = w.WriteString("<span class=\"callout\" role=\"img\" data-callout=\"" + num + "\" aria-label=\"Callout " + num + "\"></span>")
+				continue
+			}
+			id := CalloutID(n.BlockID, number)
+			_, _ = w.WriteString("<a class=\"callout\"")
+			// The explanation links back to the first badge with its number.
+			if !seen[number] {
+				_, _ = w.WriteString(" id=\"" + id + "-ref\"")
+				seen[number] = true
+			}
+			_, _ = w.WriteString(" href=\"#" + id + "\" data-callout=\"" + num + "\" aria-label=\"Callout " + num + "\"></a>")
+		}
+		_, _ = w.WriteString("\n</span>")
+	}
+	_, _ = w.WriteString("</code></pre>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *CalloutHTMLRenderer) renderCalloutRef(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*CalloutRef)
+	num := strconv.Itoa(n.Number)
+
+	_, _ = w.WriteString("<a class=\"callout\" href=\"#" + CalloutID(n.BlockID, n.Number) + "-ref\" data-callout=\"" + num + "\" aria-label=\"Back to callout " + num + "\"></a> ")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,113 @@
+package callouts
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// markerPattern matches callout markers at the end of a line, with or
+// without a comment in front: "// <1>", "# <1> <2>", "<!-- <1> -->".
+var markerPattern = regexp.MustCompile(`(?:^|\s+)(?:(?://|#|--|;+|/\*|<!--)\s*)?((?:<\d+>\s*)+)(?:\*/|-->)?\s*$`)
+
+var numberPattern = regexp.MustCompile(`<(\d+)>`)
+
+// CalloutTransformer replaces fenced code blocks that have callout markers
+// with CalloutBlocks, and links the ordered list after each one to its callouts.
+type CalloutTransformer struct {
+}
+
+// Transform converts the nodes.
+func (t *CalloutTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	// Collect all blocks to be replaced without modifying the tree.
+	var codeBlocks []*ast.FencedCodeBlock
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if cb, ok := node.(*ast.FencedCodeBlock); ok {
+			codeBlocks = append(codeBlocks, cb)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	count := 0
+	for _, cb := range codeBlocks {
+		lines, ok := parseLines(cb, source)
+		if !ok {
+			continue
+		}
+
+		count++
+		b := &CalloutBlock{
+			Language:  string(cb.Language(source)),
+			BlockID:   "callout-" + strconv.Itoa(count),
+			CodeLines: lines,
+			Explained: map[int]bool{},
+		}
+		b.SetLines(cb.Lines())
+
+		if list, ok := cb.NextSibling().(*ast.List); ok && list.IsOrdered() {
+			linkExplanations(b, list)
+		}
+
+		parent := cb.Parent()
+		parent.ReplaceChild(parent, cb, b)
+	}
+}
+
+// parseLines splits the markers off each line of the code block.
+// It returns false if there aren't any markers.
+func parseLines(cb *ast.FencedCodeBlock, source []byte) ([]CodeLine, bool) {
+	var lines []CodeLine
+	found := false
+	for i := 0; i < cb.Lines().Len(); i++ {
+		segment := cb.Lines().At(i)
+		line := strings.TrimRight(string(segment.Value(source)), "\r\n")
+
+		l := CodeLine{Text: line}
+		if m := markerPattern.FindStringSubmatchIndex(line); m != nil {
+			for _, n := range numberPattern.FindAllStringSubmatch(line[m[2]:m[3]], -1) {
+				number, _ := strconv.Atoi(n[1])
+				l.Callouts = append(l.Callouts, number)
+			}
+			l.Text = line[:m[0]]
+			found = true
+		}
+		lines = append(lines, l)
+	}
+	return lines, found
+}
+
+// linkExplanations gives each item in the list an id and a badge that
+// links back to its callout in the code.
+func linkExplanations(b *CalloutBlock, list *ast.List) {
+	list.SetAttributeString("class", []byte("callout-list"))
+
+	number := list.Start
+	if number == 0 {
+		number = 1
+	}
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		item.SetAttributeString("id", []byte(CalloutID(b.BlockID, number)))
+		b.Explained[number] = true
+
+		ref := &CalloutRef{BlockID: b.BlockID, Number: number}
+		target := item
+		if first := item.FirstChild(); first != nil && (first.Kind() == ast.KindTextBlock || first.Kind() == ast.KindParagraph) {
+			target = first
+		}
+		if target.FirstChild() != nil {
+			target.InsertBefore(target, target.FirstChild(), ref)
+		} else {
+			target.AppendChild(target, ref)
+		}
+		number++
+	}
+}
@@ -0,0 +1,21 @@
+package charts
+
+import "github.com/yuin/goldmark/ast"
+
+// ChartKind is Chart
+var ChartKind = ast.NewNodeKind("Chart")
+
+// Chart is a `chart` code fence. Its lines are the chart's spec, in YAML
+// or JSON.
+type Chart struct {
+	ast.BaseBlock
+	Line int // Line of the fence, for error messages
+}
+
+// Kind reports that this is a Chart.
+func (*Chart) Kind() ast.NodeKind { return ChartKind }
+
+// Dump dumps the contents of this block to stdout.
+func (c *Chart) Dump(src []byte, level int) {
+	ast.DumpHelper(c, src, level, nil, nil)
+}
@@ -0,0 +1,217 @@
+package charts
+
+import (
+	"fmt"
+	h "html"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The size of every chart. The SVG scales down to fit narrow pages.
+const (
+	chartWidth  = 640
+	chartHeight = 360
+	colors      = 6 // chart-1 to chart-6 in the theme
+)
+
+// palette is the light theme's chart colors, used as presentation
+// attributes so the chart still has color without the stylesheet. The
+// stylesheet overrides them with the theme's colors.
+var palette = [colors]string{"#0969da", "#bc4c00", "#1a7f37", "#8250df", "#cf222e", "#1b7c83"}
+
+// typeNames describe each type of chart for screen readers.
+var typeNames = map[string]string{
+	"bar":  "Bar chart",
+	"line": "Line chart",
+	"pie":  "Pie chart",
+}
+
+// draw returns the chart as an SVG. Series, or slices of a pie, are
+// colored chart-1 to chart-6 in turn, and text is drawn in currentColor.
+func draw(s *spec) string {
+	title := s.Title
+	if title == "" {
+		title = typeNames[s.Type]
+	}
+	names := make([]string, len(s.Series))
+	for i, series := range s.Series {
+		names[i] = series.Name
+	}
+	desc := fmt.Sprintf("%s of %s for %s.", typeNames[s.Type], strings.Join(names, ", "), strings.Join(s.Labels, ", "))
+	if s.Type == "pie" {
+		desc = fmt.Sprintf("%s of %s.", typeNames[s.Type], strings.Join(s.Labels, ", "))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 %d %d\" width=\"%d\" height=\"%d\" role=\"img\" aria-label=\"%s\">", chartWidth, chartHeight, chartWidth, chartHeight, h.EscapeString(title))
+	b.WriteString("<title>" + h.EscapeString(title) + "</title><desc>" + h.EscapeString(desc) + "</desc>")
+	b.WriteString("<g fill=\"currentColor\" font-size=\"12\">")
+
+	top := 16
+	if s.Title != "" {
+		fmt.Fprintf(&b, "<text class=\"chart-title\" x=\"%d\" y=\"24\" text-anchor=\"middle\" font-size=\"16\" font-weight=\"bold\">%s</text>", chartWidth/2, h.EscapeString(s.Title))
+		top = 44
+	}
+
+	if s.Type == "pie" {
+		drawPie(&b, s, top)
+	} else {
+		drawAxes(&b, s, top)
+	}
+
+	b.WriteString("</g></svg>\n")
+	return b.String()
+}
+
+// colorClass returns the class and fallback color attributes for the nth
+// series or slice.
+func colorClass(n int) string {
+	return fmt.Sprintf("class=\"chart-color-%d\" fill=\"%s\" stroke=\"%s\"", n%colors+1, palette[n%colors], palette[n%colors])
+}
+
+// drawAxes draws a bar or line chart, with the labels along the bottom,
+// the values up the side, and a legend if there's more than one series.
+func drawAxes(b *strings.Builder, s *spec, top int) {
+	left, right, bottom := 56, chartWidth-16, chartHeight-32
+	if len(s.Series) > 1 {
+		bottom -= 24
+		drawLegend(b, s.Series, left, chartHeight-12)
+	}
+
+	lo, hi := 0.0, 0.0
+	for _, series := range s.Series {
+		for _, v := range series.Values {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	step := niceStep(hi - lo)
+	lo, hi = math.Floor(lo/step)*step, math.Ceil(hi/step)*step
+	if hi == lo {
+		hi = lo + step
+	}
+	y := func(v float64) float64 {
+		return float64(bottom) - (v-lo)/(hi-lo)*float64(bottom-top)
+	}
+
+	// grid lines and values
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+	for i := 0; lo+float64(i)*step <= hi+step/2; i++ {
+		v := lo + float64(i)*step
+		fmt.Fprintf(b, "<line class=\"chart-grid\" x1=\"%d\" y1=\"%s\" x2=\"%d\" y2=\"%s\" stroke=\"#eaecef\"/>", left, num(y(v)), right, num(y(v)))
+		fmt.Fprintf(b, "<text x=\"%d\" y=\"%s\" text-anchor=\"end\">%s</text>", left-8, num(y(v)+4), strconv.FormatFloat(v, 'f', decimals, 64))
+	}
+	fmt.Fprintf(b, "<line class=\"chart-axis\" x1=\"%d\" y1=\"%s\" x2=\"%d\" y2=\"%s\" stroke=\"#6a737d\"/>", left, num(y(0)), right, num(y(0)))
+
+	// labels, centered in a band each
+	band := float64(right-left) / float64(len(s.Labels))
+	for i, label := range s.Labels {
+		fmt.Fprintf(b, "<text x=\"%s\" y=\"%d\" text-anchor=\"middle\">%s</text>", num(float64(left)+band*(float64(i)+.5)), bottom+20, h.EscapeString(label))
+	}
+
+	switch s.Type {
+	case "bar":
+		width := band * .8 / float64(len(s.Series))
+		for j, series := range s.Series {
+			fmt.Fprintf(b, "<g %s>", colorClass(j))
+			for i, v := range series.Values {
+				x := float64(left) + band*float64(i) + band*.1 + width*float64(j)
+				y1, y2 := y(math.Max(v, 0)), y(math.Min(v, 0))
+				fmt.Fprintf(b, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\"><title>%s, %s: %s</title></rect>",
+					num(x), num(y1), num(width), num(y2-y1), h.EscapeString(series.Name), h.EscapeString(s.Labels[i]), strconv.FormatFloat(v, 'f', -1, 64))
+			}
+			b.WriteString("</g>")
+		}
+
+	case "line":
+		for j, series := range s.Series {
+			var points []string
+			for i, v := range series.Values {
+				points = append(points, num(float64(left)+band*(float64(i)+.5))+","+num(y(v)))
+			}
+			fmt.Fprintf(b, "<g %s>", colorClass(j))
+			fmt.Fprintf(b, "<polyline points=\"%s\" fill=\"none\" stroke-width=\"2\"/>", strings.Join(points, " "))
+			for i, point := range points {
+				px, py, _ := strings.Cut(point, ",")
+				fmt.Fprintf(b, "<circle cx=\"%s\" cy=\"%s\" r=\"3\"><title>%s, %s: %s</title></circle>",
+					px, py, h.EscapeString(series.Name), h.EscapeString(s.Labels[i]), strconv.FormatFloat(series.Values[i], 'f', -1, 64))
+			}
+			b.WriteString("</g>")
+		}
+	}
+}
+
+// drawLegend draws a colored square and the name of each series in a row.
+func drawLegend(b *strings.Builder, series []series, x, y int) {
+	for j, s := range series {
+		fmt.Fprintf(b, "<rect %s x=\"%d\" y=\"%d\" width=\"12\" height=\"12\"/>", colorClass(j), x, y-10)
+		fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\">%s</text>", x+18, y, h.EscapeString(s.Name))
+		x += 18 + utf8.RuneCountInString(s.Name)*7 + 24
+	}
+}
+
+// drawPie draws a pie chart with a legend that gives each slice's share.
+func drawPie(b *strings.Builder, s *spec, top int) {
+	values := s.Series[0].Values
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+
+	radius := float64(chartHeight-top-16) / 2
+	cx, cy := 48+radius, float64(top)+radius
+
+	angle := -math.Pi / 2 // start at 12 o'clock
+	for i, v := range values {
+		label := fmt.Sprintf("%s: %s (%s%%)", s.Labels[i], strconv.FormatFloat(v, 'f', -1, 64), num(v/total*100))
+		switch {
+		case v == 0:
+		case v == total:
+			// an arc can't go all the way around
+			fmt.Fprintf(b, "<circle %s cx=\"%s\" cy=\"%s\" r=\"%s\"><title>%s</title></circle>", colorClass(i), num(cx), num(cy), num(radius), h.EscapeString(label))
+		default:
+			end := angle + v/total*2*math.Pi
+			large := 0
+			if end-angle > math.Pi {
+				large = 1
+			}
+			fmt.Fprintf(b, "<path %s d=\"M%s %s L%s %s A%s %s 0 %d 1 %s %s Z\"><title>%s</title></path>", colorClass(i),
+				num(cx), num(cy), num(cx+radius*math.Cos(angle)), num(cy+radius*math.Sin(angle)),
+				num(radius), num(radius), large, num(cx+radius*math.Cos(end)), num(cy+radius*math.Sin(end)), h.EscapeString(label))
+			angle = end
+		}
+	}
+
+	// the legend, beside the pie
+	x := int(cx+radius) + 48
+	for i, label := range s.Labels {
+		y := top + 24 + i*24
+		fmt.Fprintf(b, "<rect %s x=\"%d\" y=\"%d\" width=\"12\" height=\"12\"/>", colorClass(i), x, y-10)
+		fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\">%s (%s%%)</text>", x+18, y, h.EscapeString(label), num(values[i]/total*100))
+	}
+}
+
+// niceStep returns a round step between grid lines, like 1, 2, 5, 10, or
+// 20, that splits the span into about five parts.
+func niceStep(span float64) float64 {
+	if span <= 0 {
+		return 1
+	}
+	raw := span / 5
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// num formats a coordinate with at most two decimal places.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,25 @@
+package charts
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extender turns `chart` code fences into SVG charts. Unless NoFiles is
+// set, a chart can read its data from a CSV file. Safe mode sets NoFiles,
+// so untrusted Markdown can't read files.
+type Extender struct {
+	NoFiles bool
+}
+
+// Extend adds the transformer and the renderer.
+func (e *Extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&ChartTransformer{}, 100),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&ChartHTMLRenderer{NoFiles: e.NoFiles}, 0),
+	))
+}
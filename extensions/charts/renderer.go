@@ -0,0 +1,65 @@
+package charts
+
+import (
+	"fmt"
+	h "html"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// ChartHTMLRenderer renders charts.
+type ChartHTMLRenderer struct {
+	NoFiles bool
+}
+
+func (r *ChartHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ChartKind, r.Render)
+}
+
+// Render does the actual rendering. The chart is an SVG titled for screen
+// readers, followed by its data in a table in a collapsed <details>.
+func (r *ChartHTMLRenderer) Render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*Chart)
+
+	var data []byte
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		data = append(data, line.Value(src)...)
+	}
+	s, err := parseSpec(data, r.NoFiles)
+	if err != nil {
+		return ast.WalkStop, fmt.Errorf("chart at line %d: %w", n.Line, err)
+	}
+
+	w.WriteString("<figure class=\"chart chart-" + s.Type + "\">\n")
+	w.WriteString(draw(s))
+	w.WriteString(dataTable(s))
+	w.WriteString("</figure>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// dataTable returns the chart's data as a table in a collapsed <details>.
+func dataTable(s *spec) string {
+	var b strings.Builder
+	b.WriteString("<details class=\"chart-data\"><summary>Chart data</summary>\n<table>\n<thead>\n<tr>\n<th></th>\n")
+	for _, series := range s.Series {
+		b.WriteString("<th style=\"text-align:right\">" + h.EscapeString(series.Name) + "</th>\n")
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for i, label := range s.Labels {
+		b.WriteString("<tr>\n<th scope=\"row\">" + h.EscapeString(label) + "</th>\n")
+		for _, series := range s.Series {
+			b.WriteString("<td style=\"text-align:right\">" + strconv.FormatFloat(series.Values[i], 'f', -1, 64) + "</td>\n")
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n</details>\n")
+	return b.String()
+}
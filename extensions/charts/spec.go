@@ -0,0 +1,139 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// spec describes a chart. It's written in YAML or JSON, like:
+//
+//	type: bar
+//	title: Signups by month
+//	labels: [Jan, Feb, Mar]
+//	series:
+//	  - name: 2024
+//	    values: [120, 135, 160]
+//
+// Instead of labels and series, csv names a file whose first column holds
+// the labels and whose other columns hold a series each, named in the
+// header row.
+type spec struct {
+	Type   string   `yaml:"type"` // bar, line, or pie
+	Title  string   `yaml:"title"`
+	Labels []string `yaml:"labels"`
+	Series []series `yaml:"series"`
+	CSV    string   `yaml:"csv"`
+}
+
+type series struct {
+	Name   string    `yaml:"name"`
+	Values []float64 `yaml:"values"`
+}
+
+// parseSpec reads and checks the spec. It reads the CSV file the spec
+// names, unless noFiles is set.
+func parseSpec(data []byte, noFiles bool) (*spec, error) {
+	s := &spec{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(s); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	switch s.Type {
+	case "bar", "line", "pie":
+	case "":
+		return nil, fmt.Errorf("the spec needs a type: bar, line, or pie")
+	default:
+		return nil, fmt.Errorf("unknown type %q (use bar, line, or pie)", s.Type)
+	}
+
+	if s.CSV != "" {
+		if noFiles {
+			return nil, fmt.Errorf("charts can't read files in safe mode")
+		}
+		if len(s.Labels) > 0 || len(s.Series) > 0 {
+			return nil, fmt.Errorf("use csv or labels and series, not both")
+		}
+		if err := s.readCSV(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.Labels) == 0 {
+		return nil, fmt.Errorf("the chart has no labels")
+	}
+	if len(s.Series) == 0 {
+		return nil, fmt.Errorf("the chart has no series")
+	}
+	for i, series := range s.Series {
+		if series.Name == "" {
+			s.Series[i].Name = "Series " + strconv.Itoa(i+1)
+		}
+		if len(series.Values) != len(s.Labels) {
+			return nil, fmt.Errorf("series %q has %d values for %d labels", s.Series[i].Name, len(series.Values), len(s.Labels))
+		}
+		for _, v := range series.Values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("series %q has a value that isn't a number", s.Series[i].Name)
+			}
+		}
+	}
+
+	if s.Type == "pie" {
+		if len(s.Series) != 1 {
+			return nil, fmt.Errorf("a pie chart has one series, not %d", len(s.Series))
+		}
+		total := 0.0
+		for _, v := range s.Series[0].Values {
+			if v < 0 {
+				return nil, fmt.Errorf("a pie chart can't show negative values")
+			}
+			total += v
+		}
+		if total == 0 {
+			return nil, fmt.Errorf("a pie chart needs a value above zero")
+		}
+	}
+	return s, nil
+}
+
+// readCSV fills in the labels and series from the CSV file.
+func (s *spec) readCSV() error {
+	data, err := os.ReadFile(s.CSV)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", s.CSV, err)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", s.CSV, err)
+	}
+	if len(records) < 2 || len(records[0]) < 2 {
+		return fmt.Errorf("%s needs a header row, a column of labels, and a column of values", s.CSV)
+	}
+
+	for _, name := range records[0][1:] {
+		s.Series = append(s.Series, series{Name: strings.TrimSpace(name)})
+	}
+	for _, record := range records[1:] {
+		s.Labels = append(s.Labels, strings.TrimSpace(record[0]))
+		for i, cell := range record[1:] {
+			v, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(cell), ",", ""), 64)
+			if err != nil {
+				return fmt.Errorf("%s: %q in the %s column isn't a number", s.CSV, cell, s.Series[i].Name)
+			}
+			s.Series[i].Values = append(s.Series[i].Values, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package charts
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- ChartTransformer
+
+// ChartTransformer transforms code fences labeled `chart` into Charts.
+type ChartTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *ChartTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var (
+		charts []*ast.FencedCodeBlock // the type of block we're looking for
+		_chart = []byte("chart")      // the code fence label
+	)
+
+	// Collect all blocks to be replaced without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+
+		cb, ok := node.(*ast.FencedCodeBlock)
+		if !ok || cb.Info == nil {
+			return ast.WalkContinue, nil
+		}
+		if bytes.Equal(cb.Language(reader.Source()), _chart) {
+			charts = append(charts, cb)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// replace the old code blocks with the new ones using our type.
+	for _, cb := range charts {
+		c := &Chart{Line: bytes.Count(reader.Source()[:cb.Info.Segment.Start], []byte("\n")) + 1}
+		c.SetLines(cb.Lines())
+
+		parent := cb.Parent()
+		if parent != nil {
+			parent.ReplaceChild(parent, cb, c)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package csvtables
+
+import "github.com/yuin/goldmark/ast"
+
+// CSVTableKind is CSVTable
+var CSVTableKind = ast.NewNodeKind("CSVTable")
+
+// CSVTable is a table of comma- or tab-separated values, from a `csv` or
+// `tsv` code fence, whose lines are the data, or from a file included with
+// {{ csvtable "data/sales.csv" }}.
+type CSVTable struct {
+	ast.BaseBlock
+	Path      string // The included file, or "" for a code fence
+	Options   string // Everything after the language or path, like `max-rows=10 caption="Sales"`
+	Delimiter rune   // ',' or '\t'
+	Line      int    // Line of the fence or include, for error messages
+}
+
+// Kind reports that this is a CSVTable.
+func (*CSVTable) Kind() ast.NodeKind { return CSVTableKind }
+
+// Dump dumps the contents of this block to stdout.
+func (t *CSVTable) Dump(src []byte, level int) {
+	ast.DumpHelper(t, src, level, map[string]string{
+		"Path":    t.Path,
+		"Options": t.Options,
+	}, nil)
+}
@@ -0,0 +1,31 @@
+package csvtables
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extender turns `csv` and `tsv` code fences into tables and, unless
+// NoFiles is set, includes tables from files with lines like
+// {{ csvtable "data/sales.csv" }}. Safe mode sets NoFiles, so untrusted
+// Markdown can't read files.
+type Extender struct {
+	NoFiles bool
+}
+
+// Extend adds the parser, transformer, and renderer.
+func (e *Extender) Extend(m goldmark.Markdown) {
+	if !e.NoFiles {
+		m.Parser().AddOptions(parser.WithBlockParsers(
+			util.Prioritized(NewCSVTableParser(), 100),
+		))
+	}
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&CSVTableTransformer{}, 100),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&CSVTableHTMLRenderer{}, 0),
+	))
+}
@@ -0,0 +1,89 @@
+package csvtables
+
+import (
+	"fmt"
+	"lessonmd/extensions/internal/syntax"
+	"strconv"
+	"strings"
+)
+
+// options control how a table is shown. They're written after the
+// language or path, like `header=false align=left,right max-rows=10`.
+type options struct {
+	header    bool     // The first row is the header. Defaults to true
+	align     []string // left, right, center, or auto for each column
+	decimals  []int    // Round numbers to this many places for each column, or -1 to leave them as written
+	thousands bool     // Group the digits of numbers with commas
+	maxRows   int      // Show at most this many rows, or 0 for all of them
+	caption   string
+}
+
+// parseOptions reads the options, like `max-rows=10 caption="Sales by region"`.
+func parseOptions(s string) (options, error) {
+	o := options{header: true}
+	fields, ok := syntax.Fields(s, "\"", false)
+	if !ok {
+		return o, fmt.Errorf("a quote isn't closed in %q", s)
+	}
+
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, "=")
+		value = strings.Trim(value, "\"")
+
+		var err error
+		switch key {
+		case "header":
+			o.header, err = strconv.ParseBool(value)
+		case "thousands":
+			o.thousands, err = strconv.ParseBool(value)
+		case "decimals":
+			o.decimals = nil
+			for _, d := range strings.Split(value, ",") {
+				places := -1
+				if d != "" {
+					places, err = strconv.Atoi(d)
+					if err == nil && places < 0 {
+						err = fmt.Errorf("it's negative")
+					}
+				}
+				o.decimals = append(o.decimals, places)
+			}
+		case "max-rows":
+			o.maxRows, err = strconv.Atoi(value)
+			if err == nil && o.maxRows < 0 {
+				err = fmt.Errorf("it's negative")
+			}
+		case "caption":
+			o.caption = value
+		case "align":
+			o.align = strings.Split(value, ",")
+			for i, a := range o.align {
+				switch a {
+				case "left", "right", "center", "auto":
+				case "":
+					o.align[i] = "auto"
+				default:
+					err = fmt.Errorf("use left, right, center, or auto for each column")
+				}
+			}
+		default:
+			return o, fmt.Errorf("unknown option %q", key)
+		}
+		if err != nil {
+			return o, fmt.Errorf("invalid %s %q: %w", key, value, err)
+		}
+	}
+	return o, nil
+}
+
+// decimalsFor returns the number of decimal places for the column, or -1
+// to leave its numbers as written. A single value applies to every column.
+func (o options) decimalsFor(col int) int {
+	switch {
+	case len(o.decimals) == 1:
+		return o.decimals[0]
+	case col < len(o.decimals):
+		return o.decimals[col]
+	}
+	return -1
+}
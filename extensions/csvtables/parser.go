@@ -0,0 +1,66 @@
+package csvtables
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// includePattern matches a whole line like '{{ csvtable "data/sales.csv" max-rows=10 }}'.
+var includePattern = regexp.MustCompile(`^\{\{\s*csvtable\s+"([^"]+)"(.*?)\s*\}\}$`)
+
+type csvTableParser struct {
+}
+
+var defaultCSVTableParser = &csvTableParser{}
+
+// NewCSVTableParser returns a new BlockParser that parses csvtable includes.
+func NewCSVTableParser() parser.BlockParser {
+	return defaultCSVTableParser
+}
+
+func (p *csvTableParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *csvTableParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+
+	m := includePattern.FindSubmatch(bytes.TrimSpace(line))
+	if m == nil {
+		return nil, parser.NoChildren
+	}
+
+	t := &CSVTable{
+		Path:      string(m[1]),
+		Options:   strings.TrimSpace(string(m[2])),
+		Delimiter: ',',
+		Line:      bytes.Count(reader.Source()[:segment.Start], []byte("\n")) + 1,
+	}
+	if strings.EqualFold(filepath.Ext(t.Path), ".tsv") {
+		t.Delimiter = '\t'
+	}
+
+	reader.Advance(segment.Len())
+	return t, parser.NoChildren
+}
+
+func (p *csvTableParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	return parser.Close
+}
+
+func (p *csvTableParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+}
+
+func (p *csvTableParser) CanInterruptParagraph() bool {
+	return false
+}
+
+func (p *csvTableParser) CanAcceptIndentedLine() bool {
+	return false
+}
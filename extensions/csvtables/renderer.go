@@ -0,0 +1,208 @@
+package csvtables
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	h "html"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// numberPattern matches numbers like 42, -3.5, and 1,234,567.89.
+var numberPattern = regexp.MustCompile(`^[-+]?(\d{1,3}(,\d{3})+|\d+)(\.\d+)?$`)
+
+// CSVTableHTMLRenderer renders CSV tables.
+type CSVTableHTMLRenderer struct{}
+
+func (r *CSVTableHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(CSVTableKind, r.Render)
+}
+
+// Render does the actual rendering. The table is marked up like a
+// GitHub-flavored Markdown table, so it gets the same styles. Columns of
+// numbers are right-aligned unless the options say otherwise.
+func (r *CSVTableHTMLRenderer) Render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*CSVTable)
+
+	out, err := renderTable(n, src)
+	if err != nil {
+		return ast.WalkStop, fmt.Errorf("csv table at line %d: %w", n.Line, err)
+	}
+	w.WriteString(out)
+	return ast.WalkSkipChildren, nil
+}
+
+func renderTable(n *CSVTable, src []byte) (string, error) {
+	o, err := parseOptions(n.Options)
+	if err != nil {
+		return "", err
+	}
+
+	var data []byte
+	if n.Path != "" {
+		data, err = os.ReadFile(n.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", n.Path, err)
+		}
+	} else {
+		for i := 0; i < n.Lines().Len(); i++ {
+			line := n.Lines().At(i)
+			data = append(data, line.Value(src)...)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = n.Delimiter
+	reader.FieldsPerRecord = -1 // short rows get empty cells
+	reader.LazyQuotes = n.Delimiter == '\t'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("there are no rows")
+	}
+
+	var head []string
+	body := records
+	if o.header {
+		head, body = records[0], records[1:]
+	}
+
+	columns := 0
+	for _, record := range records {
+		if len(record) > columns {
+			columns = len(record)
+		}
+	}
+
+	// a column is numeric when every cell with something in it is a number
+	numeric := make([]bool, columns)
+	for col := range numeric {
+		numeric[col] = true
+		empty := true
+		for _, record := range body {
+			if cell := strings.TrimSpace(cellAt(record, col)); cell != "" {
+				empty = false
+				if !numberPattern.MatchString(cell) {
+					numeric[col] = false
+					break
+				}
+			}
+		}
+		numeric[col] = numeric[col] && !empty
+	}
+
+	styles := make([]string, columns)
+	for col := range styles {
+		align := "auto"
+		if col < len(o.align) {
+			align = o.align[col]
+		}
+		if align == "auto" && numeric[col] {
+			align = "right"
+		}
+		if align != "auto" {
+			styles[col] = " style=\"text-align:" + align + "\""
+		}
+	}
+
+	total := len(body)
+	if o.maxRows > 0 && total > o.maxRows {
+		body = body[:o.maxRows]
+	}
+
+	var b strings.Builder
+	b.WriteString("<div class=\"csv-table\">\n<table>\n")
+	if o.caption != "" {
+		b.WriteString("<caption>" + h.EscapeString(o.caption) + "</caption>\n")
+	}
+	if head != nil {
+		b.WriteString("<thead>\n<tr>\n")
+		for col := 0; col < columns; col++ {
+			b.WriteString("<th" + styles[col] + ">" + h.EscapeString(strings.TrimSpace(cellAt(head, col))) + "</th>\n")
+		}
+		b.WriteString("</tr>\n</thead>\n")
+	}
+	if len(body) > 0 {
+		b.WriteString("<tbody>\n")
+		for _, record := range body {
+			b.WriteString("<tr>\n")
+			for col := 0; col < columns; col++ {
+				cell := strings.TrimSpace(cellAt(record, col))
+				if numeric[col] && cell != "" {
+					cell = formatNumber(cell, o.decimalsFor(col), o.thousands)
+				}
+				b.WriteString("<td" + styles[col] + ">" + h.EscapeString(cell) + "</td>\n")
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+	if len(body) < total {
+		fmt.Fprintf(&b, "<p class=\"csv-table-note\">Showing %d of %d rows.</p>\n", len(body), total)
+	}
+	b.WriteString("</div>\n")
+	return b.String(), nil
+}
+
+// cellAt returns the cell in the column, or "" if the row is too short.
+func cellAt(record []string, col int) string {
+	if col < len(record) {
+		return record[col]
+	}
+	return ""
+}
+
+// formatNumber rounds the number to decimals places, unless decimals is
+// -1, and groups its digits if thousands is set.
+func formatNumber(s string, decimals int, thousands bool) string {
+	if decimals < 0 && !thousands {
+		return s
+	}
+
+	s = strings.ReplaceAll(s, ",", "")
+	if decimals >= 0 {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return s
+		}
+		s = strconv.FormatFloat(v, 'f', decimals, 64)
+	}
+	if thousands {
+		s = groupThousands(s)
+	}
+	return s
+}
+
+// groupThousands adds commas between groups of three digits, like 1,234,567.8.
+func groupThousands(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString("." + fraction)
+	}
+	return sign + b.String()
+}
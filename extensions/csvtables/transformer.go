@@ -0,0 +1,61 @@
+package csvtables
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- CSVTableTransformer
+
+// CSVTableTransformer transforms code fences labeled `csv` or `tsv` into
+// CSVTables.
+type CSVTableTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *CSVTableTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var blocks []*ast.FencedCodeBlock
+
+	// Collect all blocks to be replaced without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+
+		cb, ok := node.(*ast.FencedCodeBlock)
+		if !ok || cb.Info == nil {
+			return ast.WalkContinue, nil
+		}
+
+		switch string(cb.Language(reader.Source())) {
+		case "csv", "tsv":
+			blocks = append(blocks, cb)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// replace the old code blocks with the new ones using our type.
+	for _, cb := range blocks {
+		info := cb.Info.Segment
+		language, options, _ := strings.Cut(strings.TrimSpace(string(info.Value(reader.Source()))), " ")
+
+		t := &CSVTable{
+			Options:   options,
+			Delimiter: ',',
+			Line:      bytes.Count(reader.Source()[:info.Start], []byte("\n")) + 1,
+		}
+		if language == "tsv" {
+			t.Delimiter = '\t'
+		}
+		t.SetLines(cb.Lines())
+
+		parent := cb.Parent()
+		if parent != nil {
+			parent.ReplaceChild(parent, cb, t)
+		}
+	}
+}
@@ -7,15 +7,19 @@ import (
 	"github.com/yuin/goldmark/util"
 )
 
-type detailsExtender struct{}
+// Extender is the details extension.
+// Open renders every details block open, for printing.
+type Extender struct {
+	Open bool
+}
 
-var DetailsExtender = &detailsExtender{}
+var DetailsExtender = &Extender{}
 
-func (e *detailsExtender) Extend(m goldmark.Markdown) {
+func (e *Extender) Extend(m goldmark.Markdown) {
 	m.Parser().AddOptions(parser.WithBlockParsers(
 		util.Prioritized(&detailsParser{}, 100),
 	))
 	m.Renderer().AddOptions(renderer.WithNodeRenderers(
-		util.Prioritized(&detailsHTMLRenderer{}, 0),
+		util.Prioritized(&detailsHTMLRenderer{open: e.Open}, 0),
 	))
 }
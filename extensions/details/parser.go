@@ -29,6 +29,11 @@ func (a *detailsParser) Open(parent ast.Node, reader text.Reader, pc parser.Cont
 		return nil, parser.NoChildren
 	}
 
+	// "[details](url)" is a link, not a details block
+	if rest := line[len("[details"):]; len(rest) > 0 && !bytes.ContainsAny(rest[:1], " \t\r\n") {
+		return nil, parser.NoChildren
+	}
+
 	// Get the part after "[details "
 	line = bytes.TrimPrefix(line, []byte("[details "))
 
@@ -53,11 +58,12 @@ func (a *detailsParser) Open(parent ast.Node, reader text.Reader, pc parser.Cont
 }
 
 func (d *detailsParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
-	line, segment := reader.PeekLine()
+	line, _ := reader.PeekLine()
 
-	// If line is "]", it is the end of the block
+	// If line is "]", it is the end of the block. Leave the line ending so
+	// the next line isn't read as a lazy continuation of a paragraph.
 	if bytes.Equal(bytes.TrimSpace(line), []byte("]")) {
-		reader.Advance(segment.Len()) // Consume the line
+		reader.Advance(len(bytes.TrimRight(line, "\r\n")))
 		return parser.Close
 	}
 
@@ -9,6 +9,7 @@ import (
 )
 
 type detailsHTMLRenderer struct {
+	open bool // render every block open
 }
 
 func NewDetailsHTMLRenderer() renderer.NodeRenderer {
@@ -23,7 +24,7 @@ func (r *detailsHTMLRenderer) renderDetails(w util.BufWriter, source []byte, nod
 	n := node.(*Details)
 	if entering {
 		o := ""
-		if n.Open {
+		if n.Open || r.open {
 			o = " open"
 		}
 		_, _ = w.WriteString("<details" + o + "><summary>" + h.EscapeString(n.Title) + "</summary>\n")
@@ -0,0 +1,22 @@
+package diagrams
+
+import "github.com/yuin/goldmark/ast"
+
+// DiagramKind is Diagram
+var DiagramKind = ast.NewNodeKind("Diagram")
+
+// Diagram is a code fence in a language that an external command renders
+// to SVG, like dot or plantuml. Its lines are the diagram's source.
+type Diagram struct {
+	ast.BaseBlock
+	Language string
+	Line     int // Line number of the opening fence, for error messages
+}
+
+// Kind reports that this is a Diagram.
+func (*Diagram) Kind() ast.NodeKind { return DiagramKind }
+
+// Dump dumps the contents of this block to stdout.
+func (d *Diagram) Dump(src []byte, level int) {
+	ast.DumpHelper(d, src, level, map[string]string{"Language": d.Language}, nil)
+}
@@ -0,0 +1,65 @@
+package diagrams
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Cache keeps rendered SVGs in Dir, named by a hash of everything that went
+// into rendering them, so unchanged diagrams aren't rendered on every build.
+// A nil Cache, or one without a Dir, caches nothing.
+type Cache struct {
+	Dir string
+}
+
+// CacheKey returns the key for a diagram rendered from the given parts,
+// like the diagram source and the command that renders it.
+func CacheKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// the length keeps ("ab", "c") and ("a", "bc") apart
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the SVG cached under key, if there is one.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if c == nil || c.Dir == "" {
+		return nil, false
+	}
+	svg, err := os.ReadFile(filepath.Join(c.Dir, key+".svg"))
+	return svg, err == nil
+}
+
+// Put caches the SVG under key, creating the directory if needed.
+func (c *Cache) Put(key string, svg []byte) error {
+	if c == nil || c.Dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create diagram cache: %w", err)
+	}
+
+	// write to a temporary file first so a build that's interrupted, or
+	// running at the same time, never reads half a diagram
+	f, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to cache diagram: %w", err)
+	}
+	_, err = f.Write(svg)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(c.Dir, key+".svg"))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to cache diagram: %w", err)
+	}
+	return nil
+}
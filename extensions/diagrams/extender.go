@@ -0,0 +1,29 @@
+package diagrams
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extender renders code fences to SVG with local commands. Commands maps
+// a fence language to the command that renders it, like "dot" to
+// "dot -Tsvg". The command gets the diagram on standard input and writes
+// the SVG to standard output.
+type Extender struct {
+	Commands map[string]string
+	Cache    *Cache
+	Assets   string // Write the SVGs to files in this directory and link to them instead of inlining them
+	Nonce    string // Content Security Policy nonce for the <style> tags in inlined SVGs
+}
+
+// Extend adds the transformer and the renderer.
+func (e *Extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&DiagramTransformer{Commands: e.Commands}, 100),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&DiagramRenderer{Commands: e.Commands, Cache: e.Cache, Assets: e.Assets, Nonce: e.Nonce}, 0),
+	))
+}
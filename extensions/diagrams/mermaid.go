@@ -0,0 +1,175 @@
+package diagrams
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+	"go.abhg.dev/goldmark/mermaid"
+)
+
+// MermaidExtender renders Mermaid diagrams to SVG at build time
+// with the Mermaid CLI. goldmark-mermaid finds the diagrams, and
+// MermaidRenderer takes the place of its server-side renderer.
+type MermaidExtender struct {
+	CLI    string   // Path to mmdc. Defaults to mmdc on the PATH
+	Args   []string // Extra arguments for mmdc, like --puppeteerConfigFile
+	Theme  string   // Mermaid theme, like "forest"
+	Cache  *Cache
+	Assets string // Write the SVGs to files in this directory and link to them instead of inlining them
+	Nonce  string // Content Security Policy nonce for the <style> tags in inlined SVGs
+}
+
+// Extend adds goldmark-mermaid and the renderer.
+func (e *MermaidExtender) Extend(m goldmark.Markdown) {
+	(&mermaid.Extender{NoScript: true, RenderMode: mermaid.RenderModeServer}).Extend(m)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&MermaidRenderer{CLI: e.CLI, Args: e.Args, Theme: e.Theme, Cache: e.Cache, Assets: e.Assets, Nonce: e.Nonce}, 0),
+	))
+}
+
+// MermaidRenderer renders Mermaid blocks to SVG with the Mermaid CLI,
+// reusing the cached SVG when the diagram and settings haven't changed.
+type MermaidRenderer struct {
+	CLI    string
+	Args   []string
+	Theme  string
+	Cache  *Cache
+	Assets string
+	Nonce  string
+}
+
+// RegisterFuncs registers the renderer for Mermaid blocks.
+func (r *MermaidRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(mermaid.Kind, r.renderMermaid)
+}
+
+func (r *MermaidRenderer) renderMermaid(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+
+	src := blockSource(node, source)
+	_, _ = w.WriteString("<div class=\"mermaid\">")
+	if len(src) == 0 {
+		return ast.WalkContinue, nil
+	}
+
+	cli := r.CLI
+	if cli == "" {
+		cli = "mmdc"
+	}
+	key := CacheKey(append([]string{"mermaid", string(src), r.Theme, resolveCommand(cli)}, r.Args...)...)
+	svg, err := r.render(src, cli, key)
+	if err != nil {
+		return ast.WalkStop, fmt.Errorf("mermaid diagram at line %d: %w", FenceLine(node, source), err)
+	}
+	if err := writeSVG(w, svg, r.Assets, "mermaid-"+key[:16]+".svg", "Mermaid diagram", r.Nonce); err != nil {
+		return ast.WalkStop, err
+	}
+	return ast.WalkContinue, nil
+}
+
+// render returns the SVG for the diagram, from the cache under key or
+// from the CLI.
+func (r *MermaidRenderer) render(src []byte, cli, key string) ([]byte, error) {
+	if svg, ok := r.Cache.Get(key); ok {
+		return svg, nil
+	}
+
+	// mmdc reads and writes files, not standard input and output
+	input, err := os.CreateTemp("", "lessonmd-*.mmd")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(input.Name())
+	_, err = input.Write(src)
+	if closeErr := input.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write diagram: %w", err)
+	}
+	output := strings.TrimSuffix(input.Name(), ".mmd") + ".svg"
+	defer os.Remove(output)
+
+	args := []string{"--input", input.Name(), "--output", output, "--outputFormat", "svg", "--quiet"}
+	if r.Theme != "" {
+		args = append(args, "--theme", r.Theme)
+	}
+	args = append(args, r.Args...)
+
+	var out bytes.Buffer
+	cmd := exec.Command(cli, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return nil, commandError(cli, err, out.Bytes())
+	}
+
+	svg, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the SVG from %s: %w", cli, err)
+	}
+	if err := r.Cache.Put(key, svg); err != nil {
+		return nil, err
+	}
+	return svg, nil
+}
+
+// blockSource returns the contents of a block like a fenced code block.
+func blockSource(n ast.Node, source []byte) []byte {
+	var b bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		b.Write(line.Value(source))
+	}
+	return b.Bytes()
+}
+
+// FenceLine returns the line number of the opening fence of a block like
+// a fenced code block, for error messages.
+func FenceLine(n ast.Node, source []byte) int {
+	if d, ok := n.(*Diagram); ok {
+		return d.Line
+	}
+	// the fence is the line before the first line of the contents, and
+	// there is one, since empty Mermaid blocks aren't rendered
+	return bytes.Count(source[:n.Lines().At(0).Start], []byte("\n"))
+}
+
+// resolveCommand returns the file that runs for the command and when it
+// last changed, so a cache key changes along with the program, like when
+// it's upgraded or the PATH points to another one.
+func resolveCommand(name string) string {
+	file, err := exec.LookPath(name)
+	if err != nil {
+		return name
+	}
+	if real, err := filepath.EvalSymlinks(file); err == nil {
+		file = real
+	}
+	if info, err := os.Stat(file); err == nil {
+		return file + "@" + info.ModTime().UTC().Format(time.RFC3339Nano)
+	}
+	return file
+}
+
+// commandError describes a command that failed, with what it printed.
+func commandError(name string, err error, output []byte) error {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return fmt.Errorf("%s failed: %w: %s", name, err, output)
+}
@@ -0,0 +1,130 @@
+package diagrams
+
+import (
+	"bytes"
+	"fmt"
+	h "html"
+	"lessonmd/extensions/internal/syntax"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// DiagramRenderer renders Diagrams to SVG with their language's command,
+// reusing the cached SVG when the diagram and command haven't changed.
+type DiagramRenderer struct {
+	Commands map[string]string
+	Cache    *Cache
+	Assets   string
+	Nonce    string
+}
+
+// RegisterFuncs registers the renderer for Diagrams.
+func (r *DiagramRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(DiagramKind, r.renderDiagram)
+}
+
+func (r *DiagramRenderer) renderDiagram(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*Diagram)
+
+	src := blockSource(n, source)
+	// quotes keep spaces in a part, like a path to the program with
+	// spaces in it, and aren't passed on, like in a shell
+	command, _ := syntax.Fields(r.Commands[n.Language], "\"'", true)
+	parts := append([]string{n.Language, string(src)}, command...)
+	if len(command) > 0 {
+		parts = append(parts, resolveCommand(command[0]))
+	}
+	key := CacheKey(parts...)
+
+	svg, ok := r.Cache.Get(key)
+	if !ok {
+		var err error
+		svg, err = render(command, src)
+		if err == nil {
+			err = r.Cache.Put(key, svg)
+		}
+		if err != nil {
+			return ast.WalkStop, fmt.Errorf("%s diagram at line %d: %w", n.Language, FenceLine(n, source), err)
+		}
+	}
+
+	_, _ = w.WriteString("<div class=\"diagram diagram-" + h.EscapeString(n.Language) + "\">")
+	if err := writeSVG(w, svg, r.Assets, n.Language+"-"+key[:16]+".svg", n.Language+" diagram", r.Nonce); err != nil {
+		return ast.WalkStop, err
+	}
+	_, _ = w.WriteString("</div>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// render runs the command with the diagram on standard input and returns
+// the SVG it writes to standard output.
+func render(command []string, src []byte) ([]byte, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no command to render it")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(src)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, commandError(command[0], err, stderr.Bytes())
+	}
+
+	// drop the XML declaration and doctype that tools like Graphviz write
+	// first, which don't belong in HTML
+	start := bytes.Index(stdout.Bytes(), []byte("<svg"))
+	if start < 0 {
+		return nil, fmt.Errorf("%s didn't write an SVG", command[0])
+	}
+	return stdout.Bytes()[start:], nil
+}
+
+// writeSVG writes the SVG into the page or, when dir is set, to the file
+// name in dir, and writes an image that links to it. An SVG in the page
+// gets the nonce on its <style> tags, which a Content Security Policy
+// would block otherwise.
+func writeSVG(w util.BufWriter, svg []byte, dir, name, alt, nonce string) error {
+	if dir == "" {
+		_, _ = w.Write(addNonce(svg, nonce))
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create diagram directory: %w", err)
+	}
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, svg, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	url := path.Join(filepath.ToSlash(dir), name)
+	_, _ = w.WriteString("<img src=\"" + h.EscapeString(url) + "\" alt=\"" + h.EscapeString(alt) + "\">")
+	return nil
+}
+
+// styleTag matches the start of a <style> tag, but not of a tag like
+// <stylesheet>.
+var styleTag = regexp.MustCompile(`<style[\s/>]`)
+
+// addNonce adds the nonce attribute to each <style> tag in the SVG.
+func addNonce(svg []byte, nonce string) []byte {
+	if nonce == "" {
+		return svg
+	}
+	attr := []byte(" nonce=\"" + h.EscapeString(nonce) + "\"")
+	return styleTag.ReplaceAllFunc(svg, func(tag []byte) []byte {
+		end := len("<style")
+		return append(append(append([]byte{}, tag[:end]...), attr...), tag[end:]...)
+	})
+}
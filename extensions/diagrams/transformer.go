@@ -0,0 +1,53 @@
+package diagrams
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- DiagramTransformer
+
+// DiagramTransformer turns code fences in the languages that have a
+// command into Diagrams.
+type DiagramTransformer struct {
+	Commands map[string]string
+}
+
+// Transform converts the nodes.
+func (t *DiagramTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	if len(t.Commands) == 0 {
+		return
+	}
+
+	// Collect all blocks to be replaced without modifying the tree.
+	var blocks []*ast.FencedCodeBlock
+	_ = ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+		cb, ok := node.(*ast.FencedCodeBlock)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		if _, ok := t.Commands[string(cb.Language(reader.Source()))]; ok {
+			blocks = append(blocks, cb)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, cb := range blocks {
+		d := &Diagram{
+			Language: string(cb.Language(reader.Source())),
+			Line:     bytes.Count(reader.Source()[:cb.Info.Segment.Start], []byte("\n")) + 1,
+		}
+		d.SetLines(cb.Lines())
+
+		parent := cb.Parent()
+		if parent != nil {
+			parent.ReplaceChild(parent, cb, d)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package diffblocks
+
+import "github.com/yuin/goldmark/ast"
+
+//-----ast
+
+// DiffKind is DiffBlock
+var DiffKind = ast.NewNodeKind("DiffBlock")
+
+// Line kinds.
+const (
+	Context = "context"
+	Added   = "add"
+	Removed = "del"
+	Hunk    = "hunk"   // "@@ -1,3 +1,4 @@" headers
+	Header  = "header" // "--- a/file" and "+++ b/file" before the first hunk
+)
+
+// DiffBlock is a code block that shows a change to a file.
+type DiffBlock struct {
+	ast.BaseBlock
+	Language  string // Language of the code being changed, if any
+	Split     bool   // Show before and after side by side
+	DiffLines []Line
+}
+
+// Line is one line of a diff, without its marker.
+type Line struct {
+	Kind string
+	Text string
+}
+
+// Kind reports that this is a DiffBlock.
+func (*DiffBlock) Kind() ast.NodeKind { return DiffKind }
+
+// Dump dumps the contents of this block to stdout.
+func (b *DiffBlock) Dump(src []byte, level int) {
+	ast.DumpHelper(b, src, level, map[string]string{
+		"Language": b.Language,
+	}, nil)
+}
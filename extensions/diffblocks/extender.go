@@ -0,0 +1,23 @@
+package diffblocks
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type diffExtender struct{}
+
+// DiffExtender turns `diff` code fences, and fences with a `diff=true`
+// attribute, into diffs with styled added and removed lines.
+var DiffExtender = &diffExtender{}
+
+func (e *diffExtender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&DiffTransformer{}, 100),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&DiffHTMLRenderer{}, 0),
+	))
+}
@@ -0,0 +1,57 @@
+package diffblocks
+
+import (
+	h "html"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// DiffHTMLRenderer renders diffs.
+type DiffHTMLRenderer struct{}
+
+func (r *DiffHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(DiffKind, r.Render)
+}
+
+// Render does the actual rendering. Each line is a span with a class for
+// its kind. The + and - markers come from the stylesheet so they aren't
+// part of the code when it's copied.
+func (r *DiffHTMLRenderer) Render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*DiffBlock)
+
+	if !n.Split {
+		r.writeCode(w, n, "", func(l Line) bool { return true })
+		return ast.WalkSkipChildren, nil
+	}
+
+	w.WriteString("<div class=\"diff-split\">\n")
+	w.WriteString("<div class=\"diff-pane\">\n<div class=\"diff-pane-label\">Before</div>\n")
+	r.writeCode(w, n, " diff-before", func(l Line) bool { return l.Kind != Added })
+	w.WriteString("</div>\n")
+	w.WriteString("<div class=\"diff-pane\">\n<div class=\"diff-pane-label\">After</div>\n")
+	r.writeCode(w, n, " diff-after", func(l Line) bool { return l.Kind != Removed })
+	w.WriteString("</div>\n")
+	w.WriteString("</div>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// writeCode writes the lines that keep returns true.
+func (r *DiffHTMLRenderer) writeCode(w util.BufWriter, n *DiffBlock, class string, keep func(Line) bool) {
+	w.WriteString("<pre class=\"diff" + class + "\"><code class=\"")
+	if n.Language != "" {
+		w.WriteString("language-" + h.EscapeString(n.Language) + " ")
+	}
+	w.WriteString("diff\">")
+	for _, l := range n.DiffLines {
+		if !keep(l) {
+			continue
+		}
+		w.WriteString("<span class=\"diff-line diff-" + l.Kind + "\">" + h.EscapeString(l.Text) + "\n</span>")
+	}
+	w.WriteString("</code></pre>\n")
+}
@@ -0,0 +1,129 @@
+package diffblocks
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- DiffTransformer
+
+// DiffTransformer transforms code fences labeled `diff`, or with a `diff=true`
+// or `diff=split` attribute after the language, into DiffBlocks.
+type DiffTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *DiffTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+
+	// the type of block we're looking for
+	var diffBlocks []*ast.FencedCodeBlock
+	var options []*DiffBlock
+
+	// Collect all blocks to be replaced without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+
+		cb, ok := node.(*ast.FencedCodeBlock)
+		if !ok || cb.Info == nil {
+			return ast.WalkContinue, nil
+		}
+
+		// if not a diff block, move along.
+		b, ok := parseInfo(string(cb.Info.Segment.Value(reader.Source())))
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		diffBlocks = append(diffBlocks, cb)
+		options = append(options, b)
+		return ast.WalkContinue, nil
+	})
+
+	// replace the old code blocks with the new ones using our type.
+	for i, cb := range diffBlocks {
+		b := options[i]
+		var lines []string
+		for j := 0; j < cb.Lines().Len(); j++ {
+			line := cb.Lines().At(j)
+			lines = append(lines, strings.TrimRight(string(line.Value(reader.Source())), "\r\n"))
+		}
+		b.DiffLines = parseLines(lines)
+		b.SetLines(cb.Lines())
+
+		parent := cb.Parent()
+		if parent != nil {
+			parent.ReplaceChild(parent, cb, b)
+		}
+	}
+}
+
+// parseInfo reads the info string of a fence, like "diff", "diff split",
+// "go diff=true", or "go diff=split". It returns false if the fence isn't a diff.
+func parseInfo(info string) (*DiffBlock, bool) {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	b := &DiffBlock{}
+	isDiff := false
+	if fields[0] == "diff" {
+		isDiff = true
+	} else {
+		b.Language = fields[0]
+	}
+
+	for _, field := range fields[1:] {
+		switch field {
+		case "diff", "diff=true":
+			isDiff = true
+		case "diff=split":
+			isDiff = true
+			b.Split = true
+		case "split":
+			b.Split = true
+		}
+	}
+	return b, isDiff
+}
+
+// parseLines splits the markers off the lines. A "--- a/file" line
+// followed by a "+++ b/file" line before the first hunk names the files,
+// so the pair is a header and not a removed and an added line.
+func parseLines(lines []string) []Line {
+	var parsed []Line
+	inHunk := false
+	for i := 0; i < len(lines); i++ {
+		if !inHunk && i+1 < len(lines) && strings.HasPrefix(lines[i], "--- ") && strings.HasPrefix(lines[i+1], "+++ ") {
+			parsed = append(parsed, Line{Kind: Header, Text: lines[i]}, Line{Kind: Header, Text: lines[i+1]})
+			i++
+			continue
+		}
+		l := parseLine(lines[i])
+		if l.Kind == Hunk {
+			inHunk = true
+		}
+		parsed = append(parsed, l)
+	}
+	return parsed
+}
+
+// parseLine splits the marker off a line.
+func parseLine(line string) Line {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return Line{Kind: Hunk, Text: line}
+	case strings.HasPrefix(line, "+"):
+		return Line{Kind: Added, Text: line[1:]}
+	case strings.HasPrefix(line, "-"):
+		return Line{Kind: Removed, Text: line[1:]}
+	case strings.HasPrefix(line, " "):
+		return Line{Kind: Context, Text: line[1:]}
+	}
+	return Line{Kind: Context, Text: line}
+}
@@ -0,0 +1,36 @@
+package embed
+
+import (
+	"github.com/yuin/goldmark/ast"
+)
+
+// Embed is an embedded video, audio clip, terminal recording, or page.
+type Embed struct {
+	ast.BaseBlock
+	Provider string            // youtube, vimeo, video, audio, asciinema, or iframe
+	Source   string            // Video id, file path, or URL
+	Title    string            // Accessible title; defaults to a description of the provider
+	Options  map[string]string // captions, srclang, poster, start
+}
+
+// Dump implements Node.Dump.
+func (n *Embed) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Provider": n.Provider,
+		"Source":   n.Source,
+		"Title":    n.Title,
+	}, nil)
+}
+
+// KindEmbed is a NodeKind of the Embed node.
+var KindEmbed = ast.NewNodeKind("Embed")
+
+// Kind implements Node.Kind.
+func (n *Embed) Kind() ast.NodeKind {
+	return KindEmbed
+}
+
+// NewEmbed returns a new Embed node.
+func NewEmbed(provider, source, title string, options map[string]string) *Embed {
+	return &Embed{Provider: provider, Source: source, Title: title, Options: options}
+}
@@ -0,0 +1,24 @@
+package embed
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extender turns lines like '@[youtube](id)' into embedded players. When
+// NoIframes is set, '@[iframe](url)' lines are left as text. Safe mode
+// sets it, so untrusted Markdown can't frame any page it likes.
+type Extender struct {
+	NoIframes bool
+}
+
+func (e *Extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		util.Prioritized(&embedParser{noIframes: e.NoIframes}, 100),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(NewEmbedHTMLRenderer(), 0),
+	))
+}
@@ -0,0 +1,154 @@
+package embed
+
+import (
+	"bytes"
+	"lessonmd/extensions/internal/syntax"
+	"lessonmd/extensions/safe"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// embedPattern matches a whole line like '@[youtube](id "Title" start=30)'.
+var embedPattern = regexp.MustCompile(`^@\[([a-z]+)\]\((.+)\)$`)
+
+var (
+	youtubeID   = regexp.MustCompile(`^[A-Za-z0-9_-]{6,}$`)
+	vimeoID     = regexp.MustCompile(`^[0-9]+$`)
+	asciinemaID = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+)
+
+// options each provider accepts after the source.
+var providerOptions = map[string][]string{
+	"youtube":   {"start"},
+	"vimeo":     {"start"},
+	"video":     {"captions", "srclang", "poster"},
+	"audio":     {"captions", "srclang"},
+	"asciinema": {},
+	"iframe":    {"height"},
+}
+
+type embedParser struct {
+	noIframes bool
+}
+
+var defaultEmbedParser = &embedParser{}
+
+// NewEmbedParser returns a new BlockParser that parses embed shortcodes.
+func NewEmbedParser() parser.BlockParser {
+	return defaultEmbedParser
+}
+
+func (p *embedParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (p *embedParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+
+	m := embedPattern.FindSubmatch(bytes.TrimSpace(line))
+	if m == nil {
+		return nil, parser.NoChildren
+	}
+
+	provider := string(m[1])
+	allowed, ok := providerOptions[provider]
+	if !ok || provider == "iframe" && p.noIframes {
+		return nil, parser.NoChildren
+	}
+
+	fields, ok := syntax.Fields(string(m[2]), "\"", false)
+	if !ok || len(fields) == 0 {
+		return nil, parser.NoChildren
+	}
+
+	source, ok := normalizeSource(provider, fields[0])
+	if !ok {
+		return nil, parser.NoChildren
+	}
+
+	title := ""
+	options := map[string]string{}
+	for _, field := range fields[1:] {
+		key, value, isOption := strings.Cut(field, "=")
+		if !isOption || strings.HasPrefix(field, "\"") {
+			title = strings.Trim(field, "\"")
+			continue
+		}
+		if !contains(allowed, key) {
+			return nil, parser.NoChildren
+		}
+		options[key] = strings.Trim(value, "\"")
+	}
+
+	// The poster and captions are fetched by the browser like the source.
+	if !safe.IsSafeURL(options["poster"], true) || !safe.IsSafeURL(options["captions"], false) {
+		return nil, parser.NoChildren
+	}
+
+	reader.Advance(segment.Len())
+	return NewEmbed(provider, source, title, options), parser.NoChildren
+}
+
+func (p *embedParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	return parser.Close
+}
+
+func (p *embedParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+}
+
+func (p *embedParser) CanInterruptParagraph() bool {
+	return false
+}
+
+func (p *embedParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// normalizeSource checks the source for the provider. YouTube and Vimeo
+// URLs are reduced to the video id. It returns false if the source
+// can't be used.
+func normalizeSource(provider, source string) (string, bool) {
+	switch provider {
+	case "youtube":
+		if u, err := url.Parse(source); err == nil && u.Host != "" {
+			switch strings.TrimPrefix(u.Host, "www.") {
+			case "youtu.be":
+				source = strings.Trim(u.Path, "/")
+			case "youtube.com", "m.youtube.com", "youtube-nocookie.com":
+				if v := u.Query().Get("v"); v != "" {
+					source = v
+				} else {
+					source = strings.TrimPrefix(u.Path, "/embed/")
+				}
+			}
+		}
+		return source, youtubeID.MatchString(source)
+	case "vimeo":
+		if u, err := url.Parse(source); err == nil && u.Host != "" {
+			parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+			source = parts[len(parts)-1]
+		}
+		return source, vimeoID.MatchString(source)
+	case "iframe":
+		u, err := url.Parse(source)
+		return source, err == nil && (u.Scheme == "https" || u.Scheme == "http")
+	default:
+		// video, audio and asciinema take a relative path or a web URL.
+		u, err := url.Parse(source)
+		return source, err == nil && (u.Scheme == "" || u.Scheme == "https" || u.Scheme == "http")
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
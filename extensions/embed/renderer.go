@@ -0,0 +1,119 @@
+package embed
+
+import (
+	h "html"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// defaultTitles are used when the author doesn't give a title.
+// Screen readers announce an iframe by its title.
+var defaultTitles = map[string]string{
+	"youtube":   "YouTube video",
+	"vimeo":     "Vimeo video",
+	"video":     "Video",
+	"audio":     "Audio",
+	"asciinema": "Terminal recording",
+	"iframe":    "Embedded content",
+}
+
+// EmbedHTMLRenderer is a renderer.NodeRenderer implementation that
+// renders Embed nodes.
+type EmbedHTMLRenderer struct {
+	html.Config
+}
+
+// NewEmbedHTMLRenderer returns a new EmbedHTMLRenderer.
+func NewEmbedHTMLRenderer(opts ...html.Option) renderer.NodeRenderer {
+	r := &EmbedHTMLRenderer{
+		Config: html.NewConfig(),
+	}
+	for _, opt := range opts {
+		opt.SetHTMLOption(&r.Config)
+	}
+	return r
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *EmbedHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindEmbed, r.renderEmbed)
+}
+
+func (r *EmbedHTMLRenderer) renderEmbed(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*Embed)
+
+	title := n.Title
+	if title == "" {
+		title = defaultTitles[n.Provider]
+	}
+	src := h.EscapeString(n.Source)
+
+	_, _ = w.WriteString("<div class=\"embed embed-" + n.Provider + "\">\n")
+
+	switch n.Provider {
+	case "youtube":
+		url := "https://www.youtube-nocookie.com/embed/" + src
+		if start, ok := wholeNumber(n.Options["start"]); ok {
+			url += "?start=" + start
+		}
+		writeIframe(w, url, title, "")
+	case "vimeo":
+		url := "https://player.vimeo.com/video/" + src + "?dnt=1"
+		if start, ok := wholeNumber(n.Options["start"]); ok {
+			url += "#t=" + start + "s"
+		}
+		writeIframe(w, url, title, "")
+	case "iframe":
+		height, _ := wholeNumber(n.Options["height"])
+		writeIframe(w, src, title, height)
+	case "asciinema":
+		if asciinemaID.MatchString(n.Source) {
+			writeIframe(w, "https://asciinema.org/a/"+src+"/iframe", title, "")
+			break
+		}
+		// The asciinema script replaces the link with a player.
+		_, _ = w.WriteString("<div class=\"asciinema-player\" data-cast=\"" + src + "\" aria-label=\"" + h.EscapeString(title) + "\">")
+		_, _ = w.WriteString("<a href=\"" + src + "\">Download the recording</a></div>\n")
+	case "video", "audio":
+		_, _ = w.WriteString("<" + n.Provider + " controls preload=\"metadata\" src=\"" + src + "\"")
+		if poster := n.Options["poster"]; poster != "" {
+			_, _ = w.WriteString(" poster=\"" + h.EscapeString(poster) + "\"")
+		}
+		_, _ = w.WriteString(" aria-label=\"" + h.EscapeString(title) + "\">\n")
+		if captions := n.Options["captions"]; captions != "" {
+			lang := n.Options["srclang"]
+			if lang == "" {
+				lang = "en"
+			}
+			_, _ = w.WriteString("<track kind=\"captions\" src=\"" + h.EscapeString(captions) + "\" srclang=\"" + h.EscapeString(lang) + "\" label=\"Captions\" default>\n")
+		}
+		_, _ = w.WriteString("<a href=\"" + src + "\">Download the " + n.Provider + "</a>\n")
+		_, _ = w.WriteString("</" + n.Provider + ">\n")
+	}
+
+	_, _ = w.WriteString("</div>\n")
+	return ast.WalkContinue, nil
+}
+
+func writeIframe(w util.BufWriter, src, title, height string) {
+	_, _ = w.WriteString("<iframe src=\"" + src + "\" title=\"" + h.EscapeString(title) + "\"")
+	if height != "" {
+		_, _ = w.WriteString(" height=\"" + height + "\"")
+	}
+	_, _ = w.WriteString(" loading=\"lazy\" referrerpolicy=\"strict-origin-when-cross-origin\" allow=\"encrypted-media; fullscreen; picture-in-picture\" allowfullscreen></iframe>\n")
+}
+
+// wholeNumber returns the value if it's a whole number.
+func wholeNumber(value string) (string, bool) {
+	if _, err := strconv.Atoi(value); err != nil {
+		return "", false
+	}
+	return value, true
+}
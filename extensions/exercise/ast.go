@@ -0,0 +1,113 @@
+package exercise
+
+import (
+	"lessonmd/extensions/internal/syntax"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// ExerciseKind is the NodeKind for Exercise
+var ExerciseKind = ast.NewNodeKind("Exercise")
+
+// Exercise holds a prompt followed by any number of hints and a solution.
+type Exercise struct {
+	ast.BaseBlock
+	Title string
+	ID    string // Assigned by the transformer, e.g. exercise-1
+	lines syntax.BlockLines
+}
+
+// NewExercise returns a new Exercise node.
+func NewExercise(title string) *Exercise {
+	return &Exercise{
+		Title:     title,
+		BaseBlock: ast.BaseBlock{},
+	}
+}
+
+// Kind returns the kind of this node
+func (e *Exercise) Kind() ast.NodeKind {
+	return ExerciseKind
+}
+
+// Dump dumps the Exercise node to stdout
+func (e *Exercise) Dump(source []byte, level int) {
+	ast.DumpHelper(e, source, level, map[string]string{
+		"Title": e.Title,
+		"ID":    e.ID,
+	}, nil)
+}
+
+// PrefixIDs adds prefix to the id of the exercise.
+func (e *Exercise) PrefixIDs(prefix string) {
+	e.ID = prefix + e.ID
+}
+
+// AnchorID returns the id of the exercise, for links to it.
+func (e *Exercise) AnchorID() string {
+	return e.ID
+}
+
+// HintKind is the NodeKind for Hint
+var HintKind = ast.NewNodeKind("ExerciseHint")
+
+// Hint is a single hint. The transformer nests each hint inside the
+// previous one so they are revealed one at a time.
+type Hint struct {
+	ast.BaseBlock
+	Title  string
+	Number int
+	lines  syntax.BlockLines
+}
+
+// NewHint returns a new Hint node.
+func NewHint(title string) *Hint {
+	return &Hint{
+		Title:     title,
+		BaseBlock: ast.BaseBlock{},
+	}
+}
+
+// Kind returns the kind of this node
+func (h *Hint) Kind() ast.NodeKind {
+	return HintKind
+}
+
+// Dump dumps the Hint node to stdout
+func (h *Hint) Dump(source []byte, level int) {
+	ast.DumpHelper(h, source, level, map[string]string{
+		"Title":  h.Title,
+		"Number": strconv.Itoa(h.Number),
+	}, nil)
+}
+
+// SolutionKind is the NodeKind for Solution
+var SolutionKind = ast.NewNodeKind("ExerciseSolution")
+
+// Solution holds the answer to an exercise.
+type Solution struct {
+	ast.BaseBlock
+	Title string
+	lines syntax.BlockLines
+}
+
+// NewSolution returns a new Solution node.
+func NewSolution(title string) *Solution {
+	return &Solution{
+		Title:     title,
+		BaseBlock: ast.BaseBlock{},
+	}
+}
+
+// Kind returns the kind of this node
+func (s *Solution) Kind() ast.NodeKind {
+	return SolutionKind
+}
+
+// Dump dumps the Solution node to stdout
+func (s *Solution) Dump(source []byte, level int) {
+	ast.DumpHelper(s, source, level, map[string]string{
+		"Title": s.Title,
+	}, nil)
+}
@@ -0,0 +1,32 @@
+package exercise
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extender is the exercise extension.
+// HideSolutions drops every solution from the document so student
+// handouts never contain answers in the HTML source.
+// Open renders hints and solutions already revealed, for printing.
+type Extender struct {
+	HideSolutions bool
+	Open          bool
+}
+
+// Extend adds the exercise parser, transformer, and renderer.
+func (e *Extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(&exerciseParser{}, 100),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(&ExerciseTransformer{HideSolutions: e.HideSolutions}, 100),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&exerciseHTMLRenderer{open: e.Open}, 0),
+	))
+}
@@ -0,0 +1,105 @@
+package exercise
+
+import (
+	"bytes"
+	"lessonmd/extensions/internal/syntax"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	_exercise = []byte("[exercise")
+	_hint     = []byte("[hint")
+	_solution = []byte("[solution")
+)
+
+type exerciseParser struct {
+}
+
+var defaultExerciseParser = &exerciseParser{}
+
+// NewExerciseParser returns a new BlockParser for exercises, hints, and solutions
+func NewExerciseParser() parser.BlockParser {
+	return defaultExerciseParser
+}
+
+func (p *exerciseParser) Trigger() []byte {
+	return []byte("[")
+}
+
+func (p *exerciseParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+
+	if title, ok := blockTitle(line, _exercise); ok {
+		return NewExercise(title), parser.NoChildren
+	}
+
+	// Hints and solutions only make sense inside an exercise.
+	if _, ok := parent.(*Exercise); !ok {
+		return nil, parser.NoChildren
+	}
+
+	if title, ok := blockTitle(line, _hint); ok {
+		return NewHint(title), parser.NoChildren
+	}
+
+	if title, ok := blockTitle(line, _solution); ok {
+		return NewSolution(title), parser.NoChildren
+	}
+
+	return nil, parser.NoChildren
+}
+
+func (p *exerciseParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, _ := reader.PeekLine()
+	trimmed := bytes.TrimSpace(line)
+
+	// Each block sees every line before the blocks inside it do, so it
+	// counts them to know which "]" belongs to it.
+	if !linesOf(node).Closes(trimmed) {
+		return parser.Continue | parser.HasChildren
+	}
+
+	// The line is this block's "]". Leave the line ending so an enclosing
+	// exercise still sees the next line.
+	reader.Advance(len(bytes.TrimRight(line, "\r\n")))
+	return parser.Close
+}
+
+func (p *exerciseParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+}
+
+func (p *exerciseParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *exerciseParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// blockTitle reports whether line opens the given block and returns the
+// rest of the line as the title.
+func blockTitle(line, prefix []byte) (string, bool) {
+	if !bytes.HasPrefix(line, prefix) {
+		return "", false
+	}
+	rest := line[len(prefix):]
+	if len(rest) > 0 && rest[0] != ' ' && rest[0] != '\n' && rest[0] != '\r' {
+		return "", false
+	}
+	return string(bytes.TrimSpace(rest)), true
+}
+
+func linesOf(node ast.Node) *syntax.BlockLines {
+	switch n := node.(type) {
+	case *Exercise:
+		return &n.lines
+	case *Hint:
+		return &n.lines
+	case *Solution:
+		return &n.lines
+	}
+	return &syntax.BlockLines{}
+}
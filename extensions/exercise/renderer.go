@@ -0,0 +1,82 @@
+package exercise
+
+import (
+	h "html"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type exerciseHTMLRenderer struct {
+	open bool // render hints and solutions open
+}
+
+// NewExerciseHTMLRenderer returns a new renderer for exercise nodes.
+func NewExerciseHTMLRenderer() renderer.NodeRenderer {
+	return &exerciseHTMLRenderer{}
+}
+
+func (r *exerciseHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ExerciseKind, r.renderExercise)
+	reg.Register(HintKind, r.renderHint)
+	reg.Register(SolutionKind, r.renderSolution)
+}
+
+// openAttribute returns the attribute that shows a hint or solution's content.
+func (r *exerciseHTMLRenderer) openAttribute() string {
+	if r.open {
+		return " open"
+	}
+	return ""
+}
+
+func (r *exerciseHTMLRenderer) renderExercise(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Exercise)
+	if entering {
+		heading := "Exercise"
+		if n.Title != "" {
+			heading += ": " + n.Title
+		}
+		_, _ = w.WriteString("<div class=\"exercise\" id=\"" + n.ID + "\">\n")
+		_, _ = w.WriteString("  <div class=\"exercise-heading\">" + h.EscapeString(heading) + "</div>\n")
+		_, _ = w.WriteString("  <div class=\"exercise-body\">\n")
+	} else {
+		_, _ = w.WriteString("  </div>\n")
+		_, _ = w.WriteString("</div>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *exerciseHTMLRenderer) renderHint(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Hint)
+	if entering {
+		summary := "Hint " + strconv.Itoa(n.Number)
+		if n.Title != "" {
+			summary += ": " + n.Title
+		}
+		_, _ = w.WriteString("<details class=\"exercise-hint\"" + r.openAttribute() + "><summary>" + h.EscapeString(summary) + "</summary>\n")
+		_, _ = w.WriteString("<div class=\"details-content\">\n")
+	} else {
+		_, _ = w.WriteString("</div>\n")
+		_, _ = w.WriteString("</details>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *exerciseHTMLRenderer) renderSolution(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Solution)
+	if entering {
+		summary := "Solution"
+		if n.Title != "" {
+			summary = n.Title
+		}
+		_, _ = w.WriteString("<details class=\"exercise-solution\"" + r.openAttribute() + "><summary>" + h.EscapeString(summary) + "</summary>\n")
+		_, _ = w.WriteString("<div class=\"details-content\">\n")
+	} else {
+		_, _ = w.WriteString("</div>\n")
+		_, _ = w.WriteString("</details>\n")
+	}
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,64 @@
+package exercise
+
+import (
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- ExerciseTransformer
+
+// ExerciseTransformer numbers exercises and hints, nests each hint inside
+// the previous one, and removes solutions when HideSolutions is set.
+type ExerciseTransformer struct {
+	HideSolutions bool
+}
+
+// Transform converts the nodes.
+func (s *ExerciseTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var exercises []*Exercise
+
+	// Collect all exercises without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+		if e, ok := node.(*Exercise); ok {
+			exercises = append(exercises, e)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for i, e := range exercises {
+		e.ID = "exercise-" + strconv.Itoa(i+1)
+
+		var hints []*Hint
+		var solutions []*Solution
+		for c := e.FirstChild(); c != nil; c = c.NextSibling() {
+			switch n := c.(type) {
+			case *Hint:
+				hints = append(hints, n)
+			case *Solution:
+				solutions = append(solutions, n)
+			}
+		}
+
+		if s.HideSolutions {
+			for _, solution := range solutions {
+				e.RemoveChild(e, solution)
+			}
+		}
+
+		// Each hint lives at the end of the previous one, so learners
+		// have to open them in order.
+		for j, hint := range hints {
+			hint.Number = j + 1
+			if j > 0 {
+				prev := hints[j-1]
+				prev.AppendChild(prev, hint)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package figures
+
+import (
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// FigureKind is the NodeKind for Figure
+var FigureKind = ast.NewNodeKind("Figure")
+
+// Figure wraps an image, table, or code listing along with its caption.
+type Figure struct {
+	ast.BaseBlock
+	FigureType string // "image", "table", or "listing"
+	Number     int
+	ID         string
+}
+
+// NewFigure returns a new Figure node of the given type.
+func NewFigure(typ string) *Figure {
+	return &Figure{
+		FigureType: typ,
+		BaseBlock:  ast.BaseBlock{},
+	}
+}
+
+// Kind returns the kind of this node
+func (f *Figure) Kind() ast.NodeKind {
+	return FigureKind
+}
+
+// Dump dumps the Figure node to stdout
+func (f *Figure) Dump(source []byte, level int) {
+	ast.DumpHelper(f, source, level, map[string]string{
+		"FigureType": f.FigureType,
+		"Number":     strconv.Itoa(f.Number),
+		"ID":         f.ID,
+	}, nil)
+}
+
+// PrefixIDs adds prefix to the id of the figure.
+func (f *Figure) PrefixIDs(prefix string) {
+	f.ID = prefix + f.ID
+}
+
+// AnchorID returns the id of the figure, for links to it.
+func (f *Figure) AnchorID() string {
+	return f.ID
+}
+
+// Label returns the label for the figure, like "Figure 3".
+func (f *Figure) Label() string {
+	return labels[f.FigureType] + " " + strconv.Itoa(f.Number)
+}
+
+// CaptionKind is the NodeKind for Caption
+var CaptionKind = ast.NewNodeKind("FigureCaption")
+
+// Caption holds the inline caption text of a figure.
+type Caption struct {
+	ast.BaseBlock
+}
+
+// Kind returns the kind of this node
+func (c *Caption) Kind() ast.NodeKind {
+	return CaptionKind
+}
+
+// Dump dumps the Caption node to stdout
+func (c *Caption) Dump(source []byte, level int) {
+	ast.DumpHelper(c, source, level, nil, nil)
+}
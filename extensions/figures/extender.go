@@ -0,0 +1,25 @@
+package figures
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type figuresExtender struct{}
+
+// FiguresExtender is the figures extension
+var FiguresExtender = &figuresExtender{}
+
+// Extend adds the figures transformer and renderer.
+func (e *figuresExtender) Extend(m goldmark.Markdown) {
+	// Runs after the command and output transformers so their blocks
+	// can be captioned as listings too.
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&FigureTransformer{}, 200),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&figureHTMLRenderer{}, 0),
+	))
+}
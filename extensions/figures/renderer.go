@@ -0,0 +1,51 @@
+package figures
+
+import (
+	h "html"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type figureHTMLRenderer struct {
+}
+
+// NewFigureHTMLRenderer returns a new renderer for figure nodes.
+func NewFigureHTMLRenderer() renderer.NodeRenderer {
+	return &figureHTMLRenderer{}
+}
+
+func (r *figureHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(FigureKind, r.renderFigure)
+	reg.Register(CaptionKind, r.renderCaption)
+}
+
+func (r *figureHTMLRenderer) renderFigure(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Figure)
+	if entering {
+		_, _ = w.WriteString("<figure class=\"figure figure-" + n.FigureType + "\" id=\"" + h.EscapeString(n.ID) + "\">\n")
+	} else {
+		_, _ = w.WriteString("</figure>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *figureHTMLRenderer) renderCaption(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	figure := node.Parent().(*Figure)
+	if entering {
+		// Images are inline, so they don't end their own line.
+		if prev := node.PreviousSibling(); prev != nil && prev.Type() == ast.TypeInline {
+			_, _ = w.WriteString("\n")
+		}
+		_, _ = w.WriteString("<figcaption><span class=\"figure-label\">" + figure.Label())
+		if node.HasChildren() {
+			_, _ = w.WriteString(":</span> ")
+		} else {
+			_, _ = w.WriteString("</span>")
+		}
+	} else {
+		_, _ = w.WriteString("</figcaption>\n")
+	}
+	return ast.WalkContinue, nil
+}
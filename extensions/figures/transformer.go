@@ -0,0 +1,285 @@
+package figures
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// labels maps each figure type to the word used in captions.
+var labels = map[string]string{
+	"image":   "Figure",
+	"table":   "Table",
+	"listing": "Listing",
+}
+
+var (
+	// idPattern matches an id like {#fig:arch} at the end of a caption.
+	idPattern = regexp.MustCompile(`\s*\{#([^}\s]+)\}\s*$`)
+	// onlyIDPattern matches text that is nothing but an id.
+	onlyIDPattern = regexp.MustCompile(`^\s*\{#([^}\s]+)\}\s*$`)
+)
+
+// ----- FigureTransformer
+
+// FigureTransformer wraps captioned images, tables, and code listings in
+// Figure nodes and numbers them.
+type FigureTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *FigureTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var paragraphs []*ast.Paragraph
+
+	// Collect all paragraphs without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+		if p, ok := node.(*ast.Paragraph); ok {
+			paragraphs = append(paragraphs, p)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	source := reader.Source()
+	for _, p := range paragraphs {
+		if p.Parent() == nil {
+			continue // already used as a caption
+		}
+		if !imageFigure(p, source) {
+			blockFigure(p, source)
+		}
+	}
+
+	// Number the figures of each type in document order.
+	counts := map[string]int{}
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if f, ok := node.(*Figure); ok && enter {
+			counts[f.FigureType]++
+			f.Number = counts[f.FigureType]
+			if f.ID == "" {
+				f.ID = strings.ToLower(labels[f.FigureType]) + "-" + strconv.Itoa(f.Number)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// imageFigure turns a paragraph holding only an image into a figure when
+// the image has a title, an id, or a "Figure:" caption on the next line
+// or in the next paragraph.
+func imageFigure(p *ast.Paragraph, source []byte) bool {
+	img, ok := p.FirstChild().(*ast.Image)
+	if !ok {
+		return false
+	}
+
+	// An id like {#fig:arch} can follow the image.
+	rest := img.NextSibling()
+	idText, ok := rest.(*ast.Text)
+	if ok && onlyIDPattern.Match(idText.Segment.Value(source)) {
+		rest = idText.NextSibling()
+	} else {
+		idText = nil
+	}
+
+	// The line break after the image is an empty text node.
+	lineBreak, ok := rest.(*ast.Text)
+	if ok && lineBreak.Segment.Len() == 0 && (lineBreak.SoftLineBreak() || lineBreak.HardLineBreak()) {
+		rest = lineBreak.NextSibling()
+	} else {
+		lineBreak = nil
+	}
+
+	// Anything else in the paragraph has to be a "Figure:" caption.
+	if rest != nil && !strings.HasPrefix(textFrom(rest, source), "Figure:") {
+		return false
+	}
+
+	figure := NewFigure("image")
+	if idText != nil {
+		figure.ID = string(onlyIDPattern.FindSubmatch(idText.Segment.Value(source))[1])
+		p.RemoveChild(p, idText)
+	}
+	if lineBreak != nil {
+		p.RemoveChild(p, lineBreak)
+	}
+
+	caption := &Caption{}
+	switch {
+	case rest != nil:
+		line := &ast.Paragraph{}
+		for c := rest; c != nil; {
+			next := c.NextSibling()
+			line.AppendChild(line, c)
+			c = next
+		}
+		fillCaption(caption, figure, line, source, "Figure:")
+	case isCaption(p.NextSibling(), source, "Figure:"):
+		next := p.NextSibling().(*ast.Paragraph)
+		fillCaption(caption, figure, next, source, "Figure:")
+		next.Parent().RemoveChild(next.Parent(), next)
+	case len(img.Title) > 0:
+		caption.AppendChild(caption, ast.NewString(img.Title))
+	case figure.ID == "":
+		return false
+	}
+
+	parent := p.Parent()
+	parent.ReplaceChild(parent, p, figure)
+	figure.AppendChild(figure, img)
+	figure.AppendChild(figure, caption)
+	return true
+}
+
+// blockFigure wraps the table or code block next to a "Table:" or
+// "Listing:" caption paragraph. Captions above the block are checked first.
+func blockFigure(p *ast.Paragraph, source []byte) bool {
+	for _, typ := range []string{"table", "listing"} {
+		prefix := labels[typ] + ":"
+		if !hasPrefix(p, source, prefix) {
+			continue
+		}
+
+		target := p.NextSibling()
+		before := true
+		if !captionable(typ, target) {
+			target = p.PreviousSibling()
+			before = false
+		}
+		if !captionable(typ, target) {
+			return false
+		}
+
+		figure := NewFigure(typ)
+		caption := &Caption{}
+		fillCaption(caption, figure, p, source, prefix)
+
+		parent := p.Parent()
+		parent.ReplaceChild(parent, target, figure)
+		parent.RemoveChild(parent, p)
+		if before {
+			figure.AppendChild(figure, caption)
+			figure.AppendChild(figure, target)
+		} else {
+			figure.AppendChild(figure, target)
+			figure.AppendChild(figure, caption)
+		}
+		return true
+	}
+	return false
+}
+
+// captionable reports whether a block can be wrapped as the given type.
+// Listings are any raw block, like code fences, commands, and output.
+func captionable(typ string, n ast.Node) bool {
+	if n == nil {
+		return false
+	}
+	switch typ {
+	case "table":
+		_, ok := n.(*east.Table)
+		return ok
+	case "listing":
+		return n.Type() == ast.TypeBlock && n.IsRaw() && n.Kind() != ast.KindHTMLBlock
+	}
+	return false
+}
+
+// isCaption reports whether n is a paragraph that starts with prefix.
+func isCaption(n ast.Node, source []byte, prefix string) bool {
+	p, ok := n.(*ast.Paragraph)
+	return ok && hasPrefix(p, source, prefix)
+}
+
+// fillCaption moves the caption text from src into the caption, removing
+// the prefix and taking an id like {#fig:arch} off the end.
+func fillCaption(caption *Caption, figure *Figure, src ast.Node, source []byte, prefix string) {
+	trimLeading(src, source, len(prefix))
+	if id := trailingID(src, source); id != "" {
+		figure.ID = id
+	}
+	moveChildren(caption, src)
+}
+
+// textFrom returns the text of the consecutive text nodes starting at c,
+// up to the end of the line.
+func textFrom(c ast.Node, source []byte) string {
+	var b strings.Builder
+	for ; c != nil; c = c.NextSibling() {
+		t, ok := c.(*ast.Text)
+		if !ok {
+			break
+		}
+		b.Write(t.Segment.Value(source))
+		if t.SoftLineBreak() || t.HardLineBreak() {
+			break
+		}
+	}
+	return b.String()
+}
+
+// hasPrefix reports whether the text of n starts with prefix. The inline
+// parser may split the prefix across several text nodes.
+func hasPrefix(n ast.Node, source []byte, prefix string) bool {
+	return strings.HasPrefix(textFrom(n.FirstChild(), source), prefix)
+}
+
+// trimLeading removes count bytes and any spaces after them from the
+// leading text nodes of n.
+func trimLeading(n ast.Node, source []byte, count int) {
+	for c := n.FirstChild(); c != nil; {
+		t, ok := c.(*ast.Text)
+		if !ok {
+			return
+		}
+		value := t.Segment.Value(source)
+		if count < len(value) {
+			value = value[count:]
+			trimmed := strings.TrimLeft(string(value), " ")
+			t.Segment = t.Segment.WithStart(t.Segment.Stop - len(trimmed))
+			if len(trimmed) > 0 || t.SoftLineBreak() || t.HardLineBreak() {
+				return
+			}
+			count = 0
+		} else {
+			count -= len(value)
+		}
+		next := c.NextSibling()
+		n.RemoveChild(n, c)
+		c = next
+	}
+}
+
+// trailingID removes an id like {#fig:arch} from the end of n and returns it.
+func trailingID(n ast.Node, source []byte) string {
+	t, ok := n.LastChild().(*ast.Text)
+	if !ok {
+		return ""
+	}
+	m := idPattern.FindSubmatchIndex(t.Segment.Value(source))
+	if m == nil {
+		return ""
+	}
+	id := string(t.Segment.Value(source)[m[2]:m[3]])
+	t.Segment = t.Segment.WithStop(t.Segment.Start + m[0])
+	if t.Segment.Len() == 0 {
+		n.RemoveChild(n, t)
+	}
+	return id
+}
+
+// moveChildren moves every child of src to the end of dst.
+func moveChildren(dst, src ast.Node) {
+	for c := src.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		dst.AppendChild(dst, c)
+		c = next
+	}
+}
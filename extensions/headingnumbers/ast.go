@@ -0,0 +1,29 @@
+package headingnumbers
+
+import "github.com/yuin/goldmark/ast"
+
+// HeadingNumber is the hierarchical number shown at the start of a heading.
+type HeadingNumber struct {
+	ast.BaseInline
+	Number string // e.g. "1.2.3"
+}
+
+// Dump implements Node.Dump.
+func (n *HeadingNumber) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Number": n.Number,
+	}, nil)
+}
+
+// KindHeadingNumber is a NodeKind of the HeadingNumber node.
+var KindHeadingNumber = ast.NewNodeKind("HeadingNumber")
+
+// Kind implements Node.Kind.
+func (n *HeadingNumber) Kind() ast.NodeKind {
+	return KindHeadingNumber
+}
+
+// NewHeadingNumber returns a new HeadingNumber node.
+func NewHeadingNumber(number string) *HeadingNumber {
+	return &HeadingNumber{Number: number}
+}
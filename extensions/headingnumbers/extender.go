@@ -0,0 +1,25 @@
+package headingnumbers
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type headingNumbersExtender struct {
+}
+
+// HeadingNumbersExtender is an extension that numbers headings like 1, 1.1, and 1.1.2.
+var HeadingNumbersExtender = &headingNumbersExtender{}
+
+func (e *headingNumbersExtender) Extend(m goldmark.Markdown) {
+	// Runs after steps turns its "## Step" headings into steps, so they
+	// aren't numbered, and before cross-references read the numbers.
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&HeadingNumberTransformer{}, 250),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(NewHeadingNumberHTMLRenderer(), 0),
+	))
+}
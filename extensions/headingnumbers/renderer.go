@@ -0,0 +1,38 @@
+package headingnumbers
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// HeadingNumberHTMLRenderer is a renderer.NodeRenderer implementation that
+// renders HeadingNumber nodes.
+type HeadingNumberHTMLRenderer struct {
+	html.Config
+}
+
+// NewHeadingNumberHTMLRenderer returns a new HeadingNumberHTMLRenderer.
+func NewHeadingNumberHTMLRenderer(opts ...html.Option) renderer.NodeRenderer {
+	r := &HeadingNumberHTMLRenderer{
+		Config: html.NewConfig(),
+	}
+	for _, opt := range opts {
+		opt.SetHTMLOption(&r.Config)
+	}
+	return r
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *HeadingNumberHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindHeadingNumber, r.renderHeadingNumber)
+}
+
+func (r *HeadingNumberHTMLRenderer) renderHeadingNumber(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n := node.(*HeadingNumber)
+		_, _ = w.WriteString("<span class=\"heading-number\">" + n.Number + "</span> ")
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,89 @@
+package headingnumbers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- HeadingNumberTransformer
+
+// HeadingNumberTransformer adds a HeadingNumber to the start of each heading.
+type HeadingNumberTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *HeadingNumberTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var headings []*ast.Heading
+
+	// Collect all headings without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if h, ok := node.(*ast.Heading); ok && enter {
+			headings = append(headings, h)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for i, number := range SectionNumbers(headings) {
+		if number == "" {
+			continue
+		}
+		h := headings[i]
+		if h.FirstChild() == nil {
+			h.AppendChild(h, NewHeadingNumber(number))
+		} else {
+			h.InsertBefore(h, h.FirstChild(), NewHeadingNumber(number))
+		}
+	}
+}
+
+// SectionNumbers returns hierarchical numbers like "2.3" for each heading.
+// Numbering starts at the highest heading level in the document. A lone
+// heading at that level at the start of the document is its title and
+// gets no number.
+func SectionNumbers(headings []*ast.Heading) []string {
+	numbers := make([]string, len(headings))
+	if len(headings) == 0 {
+		return numbers
+	}
+
+	top := 6
+	count := 0
+	for _, h := range headings {
+		if h.Level < top {
+			top, count = h.Level, 0
+		}
+		if h.Level == top {
+			count++
+		}
+	}
+
+	first := 0
+	if count == 1 && headings[0].Level == top && len(headings) > 1 {
+		first = 1
+		top = 6
+		for _, h := range headings[1:] {
+			if h.Level < top {
+				top = h.Level
+			}
+		}
+	}
+
+	var counters [7]int
+	for i := first; i < len(headings); i++ {
+		level := headings[i].Level
+		counters[level]++
+		for l := level + 1; l < len(counters); l++ {
+			counters[l] = 0
+		}
+		parts := make([]string, 0, level-top+1)
+		for l := top; l <= level; l++ {
+			parts = append(parts, strconv.Itoa(counters[l]))
+		}
+		numbers[i] = strings.Join(parts, ".")
+	}
+	return numbers
+}
@@ -0,0 +1,24 @@
+package idprefix
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extender namespaces every id in the document with Prefix, so several
+// documents can be combined on one page without their ids colliding.
+type Extender struct {
+	Prefix string
+}
+
+// Extend adds the id prefix transformer.
+func (e *Extender) Extend(m goldmark.Markdown) {
+	if e.Prefix == "" {
+		return
+	}
+	// Runs last so every other transformer has assigned its ids.
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&IDPrefixTransformer{Prefix: e.Prefix}, 1000),
+	))
+}
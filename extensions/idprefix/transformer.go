@@ -0,0 +1,75 @@
+package idprefix
+
+import (
+	"bytes"
+	"net/url"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Prefixer is implemented by nodes that render ids, or links to ids,
+// that aren't stored in the node's "id" attribute.
+type Prefixer interface {
+	PrefixIDs(prefix string)
+}
+
+// Anchor is implemented by Prefixers with an id that readers can link to,
+// like "[the exercise](#exercise-1)", so those links get the prefix too.
+type Anchor interface {
+	AnchorID() string
+}
+
+// ----- IDPrefixTransformer
+
+// IDPrefixTransformer adds Prefix to the "id" attribute of every node,
+// like the ones generated for headings, and to the ids of every Prefixer.
+// Links like "[Setup](#setup)" to any of those ids get the prefix too.
+type IDPrefixTransformer struct {
+	Prefix string
+}
+
+// Transform converts the nodes.
+func (s *IDPrefixTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	ids := map[string]bool{}
+	var links []*ast.Link
+
+	_ = ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+
+		if id, ok := node.AttributeString("id"); ok {
+			switch v := id.(type) {
+			case []byte:
+				ids[string(v)] = true
+				node.SetAttributeString("id", append([]byte(s.Prefix), v...))
+			case string:
+				ids[v] = true
+				node.SetAttributeString("id", s.Prefix+v)
+			}
+		}
+
+		if a, ok := node.(Anchor); ok {
+			ids[a.AnchorID()] = true
+		}
+		if p, ok := node.(Prefixer); ok {
+			p.PrefixIDs(s.Prefix)
+		}
+
+		if link, ok := node.(*ast.Link); ok && bytes.HasPrefix(link.Destination, []byte("#")) {
+			links = append(links, link)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// Links can come before the ids they point to, so they're changed
+	// once every id is known.
+	for _, link := range links {
+		fragment := string(link.Destination[1:])
+		if unescaped, err := url.PathUnescape(fragment); err == nil && ids[unescaped] || ids[fragment] {
+			link.Destination = []byte("#" + s.Prefix + fragment)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package syntax holds the small parsing helpers that several extensions
+// share.
+package syntax
+
+import "bytes"
+
+// BlockLines follows the lines inside a bracketed block, like [steps or
+// [exercise, so the block closes at its own "]" and not at one that
+// closes a block nested inside it or that's part of a fenced code block.
+// The zero value is ready to use.
+type BlockLines struct {
+	depth int    // Number of open blocks inside, like [hint or [details
+	fence []byte // The fence of the code block the line is in, if any
+}
+
+// Closes reports whether the line closes the block and updates the state.
+// Call it once for every line after the one that opens the block.
+func (s *BlockLines) Closes(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	switch {
+	case s.fence != nil:
+		if bytes.HasPrefix(line, s.fence) && len(bytes.Trim(line, string(s.fence[:1]))) == 0 {
+			s.fence = nil
+		}
+	case bytes.HasPrefix(line, []byte("```")) || bytes.HasPrefix(line, []byte("~~~")):
+		n := 3
+		for n < len(line) && line[n] == line[0] {
+			n++
+		}
+		s.fence = line[:n:n]
+	case isOpener(line):
+		s.depth++
+	case bytes.Equal(line, []byte("]")):
+		if s.depth == 0 {
+			return true
+		}
+		s.depth--
+	}
+	return false
+}
+
+// openers are the names of the blocks that open with "[name" and close
+// with "]".
+var openers = map[string]bool{
+	"details":  true,
+	"exercise": true,
+	"hint":     true,
+	"quiz":     true,
+	"solution": true,
+	"steps":    true,
+}
+
+// isOpener reports whether line opens a bracketed block, like "[hint" or
+// "[details Title". Lines that start with a link, like "[details](url)",
+// don't.
+func isOpener(line []byte) bool {
+	if len(line) < 2 || line[0] != '[' {
+		return false
+	}
+	name := line[1:]
+	if i := bytes.IndexAny(name, " \t"); i >= 0 {
+		name = name[:i]
+	}
+	return openers[string(name)]
+}
@@ -0,0 +1,43 @@
+package syntax
+
+import "strings"
+
+// Fields splits s at spaces and tabs. Text between a pair of the quotes,
+// like `caption="Sales by region"`, stays in one field. The quotes are
+// kept in the field unless unquote is set, like for the arguments of a
+// command. It returns false if a quote isn't closed.
+func Fields(s, quotes string, unquote bool) ([]string, bool) {
+	var fields []string
+	var field strings.Builder
+	inField := false // so a quoted empty string, like '', is a field
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+			if !unquote {
+				field.WriteRune(r)
+			}
+		case quote != 0:
+			field.WriteRune(r)
+		case strings.ContainsRune(quotes, r):
+			quote, inField = r, true
+			if !unquote {
+				field.WriteRune(r)
+			}
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, quote == 0
+}
@@ -0,0 +1,33 @@
+package kbd
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Kbd is a keyboard shortcut made of one or more keys.
+type Kbd struct {
+	ast.BaseInline
+	Keys []string // Key names as written, e.g. "Ctrl", "shift", "P"
+}
+
+// Dump implements Node.Dump.
+func (n *Kbd) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Keys": strings.Join(n.Keys, "+"),
+	}, nil)
+}
+
+// KindKbd is a NodeKind of the Kbd node.
+var KindKbd = ast.NewNodeKind("Kbd")
+
+// Kind implements Node.Kind.
+func (n *Kbd) Kind() ast.NodeKind {
+	return KindKbd
+}
+
+// NewKbd returns a new Kbd node.
+func NewKbd(keys []string) *Kbd {
+	return &Kbd{Keys: keys}
+}
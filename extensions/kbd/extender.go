@@ -0,0 +1,25 @@
+package kbd
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type kbdExtender struct {
+}
+
+// KbdExtender is an extension that turns expressions like '[[Ctrl+Shift+P]]'
+// or '++ctrl+shift+p++' into <kbd> elements.
+var KbdExtender = &kbdExtender{}
+
+func (e *kbdExtender) Extend(m goldmark.Markdown) {
+	// Runs before the link parser so "[[" isn't treated as a link.
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		util.Prioritized(NewKbdParser(), 100),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(NewKbdHTMLRenderer(), 0),
+	))
+}
@@ -0,0 +1,81 @@
+package kbd
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+type kbdParser struct {
+}
+
+var defaultKbdParser = &kbdParser{}
+
+// NewKbdParser return a new InlineParser that parses keyboard shortcuts.
+func NewKbdParser() parser.InlineParser {
+	return defaultKbdParser
+}
+
+func (s *kbdParser) Trigger() []byte {
+	return []byte{'[', '+'}
+}
+
+func (s *kbdParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	var closer []byte
+	switch {
+	case bytes.HasPrefix(line, []byte("[[")):
+		closer = []byte("]]")
+	case bytes.HasPrefix(line, []byte("++")):
+		// Don't match the end of words like C++.
+		before := block.PrecendingCharacter()
+		if unicode.IsLetter(before) || unicode.IsDigit(before) {
+			return nil
+		}
+		closer = []byte("++")
+	default:
+		return nil
+	}
+
+	end := bytes.Index(line[2:], closer)
+	if end <= 0 {
+		return nil
+	}
+
+	keys := splitKeys(line[2 : 2+end])
+	if keys == nil {
+		return nil
+	}
+
+	block.Advance(2 + end + len(closer))
+	return NewKbd(keys)
+}
+
+func (s *kbdParser) CloseBlock(parent ast.Node, pc parser.Context) {
+	// nothing to do
+}
+
+// splitKeys splits "Ctrl+Shift+P" into its keys. It returns nil if any key
+// is empty or contains anything other than letters, digits, or dashes.
+func splitKeys(shortcut []byte) []string {
+	var keys []string
+	for _, key := range bytes.Split(shortcut, []byte("+")) {
+		if len(key) == 0 {
+			return nil
+		}
+		for rest := key; len(rest) > 0; {
+			r, size := utf8.DecodeRune(rest)
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' {
+				return nil
+			}
+			rest = rest[size:]
+		}
+		keys = append(keys, string(key))
+	}
+	return keys
+}
@@ -0,0 +1,109 @@
+package kbd
+
+import (
+	h "html"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// keyLabels maps lowercase key names to the label shown to readers.
+var keyLabels = map[string]string{
+	"ctrl":      "Ctrl",
+	"control":   "Ctrl",
+	"shift":     "Shift",
+	"alt":       "Alt",
+	"opt":       "Option",
+	"option":    "Option",
+	"cmd":       "Cmd",
+	"command":   "Cmd",
+	"meta":      "Meta",
+	"win":       "Win",
+	"windows":   "Win",
+	"super":     "Super",
+	"fn":        "Fn",
+	"enter":     "Enter",
+	"return":    "Return",
+	"esc":       "Esc",
+	"escape":    "Esc",
+	"tab":       "Tab",
+	"space":     "Space",
+	"backspace": "Backspace",
+	"del":       "Del",
+	"delete":    "Del",
+	"ins":       "Ins",
+	"insert":    "Ins",
+	"home":      "Home",
+	"end":       "End",
+	"pageup":    "Page Up",
+	"page-up":   "Page Up",
+	"pagedown":  "Page Down",
+	"page-down": "Page Down",
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"plus":      "+",
+	"minus":     "-",
+}
+
+// KbdHTMLRenderer is a renderer.NodeRenderer implementation that
+// renders Kbd nodes.
+type KbdHTMLRenderer struct {
+	html.Config
+}
+
+// NewKbdHTMLRenderer returns a new KbdHTMLRenderer.
+func NewKbdHTMLRenderer(opts ...html.Option) renderer.NodeRenderer {
+	r := &KbdHTMLRenderer{
+		Config: html.NewConfig(),
+	}
+	for _, opt := range opts {
+		opt.SetHTMLOption(&r.Config)
+	}
+	return r
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *KbdHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindKbd, r.renderKbd)
+}
+
+func (r *KbdHTMLRenderer) renderKbd(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*Kbd)
+
+	_, _ = w.WriteString("<kbd class=\"keys\">")
+	for i, key := range n.Keys {
+		if i > 0 {
+			_, _ = w.WriteString("+")
+		}
+		// Mod is Cmd on macOS and Ctrl elsewhere. The keys script picks
+		// the right one; without it readers see both.
+		if strings.EqualFold(key, "mod") {
+			_, _ = w.WriteString("<kbd class=\"key-mod\" data-key-mac=\"Cmd\" data-key-other=\"Ctrl\">Ctrl/Cmd</kbd>")
+			continue
+		}
+		_, _ = w.WriteString("<kbd>" + h.EscapeString(keyLabel(key)) + "</kbd>")
+	}
+	_, _ = w.WriteString("</kbd>")
+	return ast.WalkSkipChildren, nil
+}
+
+// keyLabel returns the display label for a key name.
+func keyLabel(key string) string {
+	if label, ok := keyLabels[strings.ToLower(key)]; ok {
+		return label
+	}
+	// Single letters and function keys like f5 are upper case.
+	runes := []rune(key)
+	if len(runes) == 1 || (len(key) <= 3 && (key[0] == 'f' || key[0] == 'F') && strings.Trim(key[1:], "0123456789") == "") {
+		return strings.ToUpper(key)
+	}
+	return strings.ToUpper(string(runes[0])) + string(runes[1:])
+}
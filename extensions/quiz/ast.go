@@ -0,0 +1,169 @@
+package quiz
+
+import (
+	"lessonmd/extensions/internal/syntax"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// QuizKind is the NodeKind for Quiz
+var QuizKind = ast.NewNodeKind("Quiz")
+
+// Quiz is a single knowledge check question. Its children are the
+// Prompt, any other blocks of the question, and the list of choices.
+type Quiz struct {
+	ast.BaseBlock
+	Question string
+	ID       string // Assigned by the transformer, e.g. quiz-1
+	Multiple bool   // More than one choice is correct
+	lines    syntax.BlockLines
+}
+
+// NewQuiz returns a new Quiz node.
+func NewQuiz(question string) *Quiz {
+	return &Quiz{
+		Question:  question,
+		BaseBlock: ast.BaseBlock{},
+	}
+}
+
+// Kind returns the kind of this node
+func (q *Quiz) Kind() ast.NodeKind {
+	return QuizKind
+}
+
+// Dump dumps the Quiz node to stdout
+func (q *Quiz) Dump(source []byte, level int) {
+	ast.DumpHelper(q, source, level, map[string]string{
+		"Question": q.Question,
+		"ID":       q.ID,
+		"Multiple": strconv.FormatBool(q.Multiple),
+	}, nil)
+}
+
+// PrefixIDs adds prefix to the id of the quiz.
+func (q *Quiz) PrefixIDs(prefix string) {
+	q.ID = prefix + q.ID
+}
+
+// AnchorID returns the id of the quiz, for links to it.
+func (q *Quiz) AnchorID() string {
+	return q.ID
+}
+
+// PromptKind is the NodeKind for Prompt
+var PromptKind = ast.NewNodeKind("QuizPrompt")
+
+// Prompt holds the question from the first line of the quiz, which is
+// parsed as inline Markdown.
+type Prompt struct {
+	ast.BaseBlock
+}
+
+// Kind returns the kind of this node
+func (p *Prompt) Kind() ast.NodeKind {
+	return PromptKind
+}
+
+// Dump dumps the Prompt node to stdout
+func (p *Prompt) Dump(source []byte, level int) {
+	ast.DumpHelper(p, source, level, nil, nil)
+}
+
+// ChoicesKind is the NodeKind for Choices
+var ChoicesKind = ast.NewNodeKind("QuizChoices")
+
+// Choices is the list of answers for a quiz.
+type Choices struct {
+	ast.BaseBlock
+}
+
+// Kind returns the kind of this node
+func (c *Choices) Kind() ast.NodeKind {
+	return ChoicesKind
+}
+
+// Dump dumps the Choices node to stdout
+func (c *Choices) Dump(source []byte, level int) {
+	ast.DumpHelper(c, source, level, nil, nil)
+}
+
+// ChoiceKind is the NodeKind for Choice
+var ChoiceKind = ast.NewNodeKind("QuizChoice")
+
+// Choice is a single answer. The first child is the ChoiceLabel, and an
+// optional Explanation follows.
+type Choice struct {
+	ast.BaseBlock
+	QuizID   string
+	Index    int
+	Correct  bool
+	Multiple bool
+}
+
+// Kind returns the kind of this node
+func (c *Choice) Kind() ast.NodeKind {
+	return ChoiceKind
+}
+
+// Dump dumps the Choice node to stdout
+func (c *Choice) Dump(source []byte, level int) {
+	ast.DumpHelper(c, source, level, map[string]string{
+		"Index":   strconv.Itoa(c.Index),
+		"Correct": strconv.FormatBool(c.Correct),
+	}, nil)
+}
+
+// PrefixIDs adds prefix to the quiz id the choice uses for its input.
+func (c *Choice) PrefixIDs(prefix string) {
+	c.QuizID = prefix + c.QuizID
+}
+
+// ChoiceLabelKind is the NodeKind for ChoiceLabel
+var ChoiceLabelKind = ast.NewNodeKind("QuizChoiceLabel")
+
+// ChoiceLabel holds the inline text of a choice.
+type ChoiceLabel struct {
+	ast.BaseBlock
+	InputID string
+}
+
+// Kind returns the kind of this node
+func (l *ChoiceLabel) Kind() ast.NodeKind {
+	return ChoiceLabelKind
+}
+
+// Dump dumps the ChoiceLabel node to stdout
+func (l *ChoiceLabel) Dump(source []byte, level int) {
+	ast.DumpHelper(l, source, level, nil, nil)
+}
+
+// PrefixIDs adds prefix to the id of the input the label is for.
+func (l *ChoiceLabel) PrefixIDs(prefix string) {
+	l.InputID = prefix + l.InputID
+}
+
+// ExplanationKind is the NodeKind for Explanation
+var ExplanationKind = ast.NewNodeKind("QuizExplanation")
+
+// Explanation is shown after the learner picks the choice it belongs to.
+type Explanation struct {
+	ast.BaseBlock
+	ID string
+}
+
+// Kind returns the kind of this node
+func (e *Explanation) Kind() ast.NodeKind {
+	return ExplanationKind
+}
+
+// Dump dumps the Explanation node to stdout
+func (e *Explanation) Dump(source []byte, level int) {
+	ast.DumpHelper(e, source, level, nil, nil)
+}
+
+// PrefixIDs adds prefix to the id of the explanation.
+func (e *Explanation) PrefixIDs(prefix string) {
+	e.ID = prefix + e.ID
+}
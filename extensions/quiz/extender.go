@@ -0,0 +1,32 @@
+package quiz
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type quizExtender struct{}
+
+// QuizExtender is the quiz extension
+var QuizExtender = &quizExtender{}
+
+// Extend adds the quiz parsers, transformer, and renderer.
+func (e *quizExtender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(&quizParser{}, 100),
+		),
+		// Before the link parser, which would take the "[".
+		parser.WithInlineParsers(
+			util.Prioritized(NewCheckBoxParser(), 0),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(&QuizTransformer{}, 100),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&quizHTMLRenderer{}, 0),
+	))
+}
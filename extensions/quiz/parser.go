@@ -0,0 +1,111 @@
+package quiz
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+type quizParser struct {
+}
+
+var defaultQuizParser = &quizParser{}
+
+// NewQuizParser returns a new BlockParser for quizzes
+func NewQuizParser() parser.BlockParser {
+	return defaultQuizParser
+}
+
+func (q *quizParser) Trigger() []byte {
+	return []byte("[quiz")
+}
+
+func (q *quizParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+
+	if !bytes.HasPrefix(line, []byte("[quiz ")) {
+		return nil, parser.NoChildren
+	}
+
+	// The rest of the line is the question, which goldmark parses as
+	// inline Markdown along with the other blocks.
+	question := text.NewSegment(segment.Start+len("[quiz "), segment.Stop)
+	question = question.TrimLeftSpace(reader.Source())
+	question = question.TrimRightSpace(reader.Source())
+	quiz := NewQuiz(string(question.Value(reader.Source())))
+	prompt := &Prompt{}
+	prompt.Lines().Append(question)
+	quiz.AppendChild(quiz, prompt)
+
+	return quiz, parser.NoChildren
+}
+
+func (q *quizParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, _ := reader.PeekLine()
+
+	// The block ends at its own "]", not at one in a code block in a
+	// choice. Leave the line ending so the next line isn't read as a lazy
+	// continuation of a paragraph.
+	if node.(*Quiz).lines.Closes(line) {
+		reader.Advance(len(bytes.TrimRight(line, "\r\n")))
+		return parser.Close
+	}
+
+	return parser.Continue | parser.HasChildren
+}
+
+func (q *quizParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+}
+
+func (q *quizParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (q *quizParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+var checkBoxPattern = regexp.MustCompile(`^\[([\sxX])\]\s*`)
+
+type checkBoxParser struct {
+}
+
+// NewCheckBoxParser returns a new InlineParser for the "[ ]" and "[x]"
+// that start each choice. It works like the tasklist extension's parser,
+// and makes the same nodes, but only inside quizzes, so quizzes work
+// whether or not that extension is on.
+func NewCheckBoxParser() parser.InlineParser {
+	return &checkBoxParser{}
+}
+
+func (c *checkBoxParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (c *checkBoxParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	// The checkbox must start the first block of an item in a list that's
+	// directly inside a quiz.
+	item, ok := parent.Parent().(*ast.ListItem)
+	if !ok || item.FirstChild() != parent || parent.HasChildren() {
+		return nil
+	}
+	if _, ok := item.Parent().Parent().(*Quiz); !ok {
+		return nil
+	}
+
+	line, _ := block.PeekLine()
+	m := checkBoxPattern.FindSubmatchIndex(line)
+	if m == nil {
+		return nil
+	}
+	value := line[m[2]]
+	block.Advance(m[1])
+	return east.NewTaskCheckBox(value == 'x' || value == 'X')
+}
+
+func (c *checkBoxParser) CloseBlock(parent ast.Node, pc parser.Context) {
+}
@@ -0,0 +1,103 @@
+package quiz
+
+import (
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type quizHTMLRenderer struct {
+}
+
+// NewQuizHTMLRenderer returns a new renderer for quiz nodes.
+func NewQuizHTMLRenderer() renderer.NodeRenderer {
+	return &quizHTMLRenderer{}
+}
+
+func (r *quizHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(QuizKind, r.renderQuiz)
+	reg.Register(PromptKind, r.renderPrompt)
+	reg.Register(ChoicesKind, r.renderChoices)
+	reg.Register(ChoiceKind, r.renderChoice)
+	reg.Register(ChoiceLabelKind, r.renderChoiceLabel)
+	reg.Register(ExplanationKind, r.renderExplanation)
+}
+
+func (r *quizHTMLRenderer) renderQuiz(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Quiz)
+	if entering {
+		_, _ = w.WriteString("<div class=\"quiz\" id=\"" + n.ID + "\">\n")
+		_, _ = w.WriteString("<fieldset>\n")
+	} else {
+		// The check button stays hidden until the quiz script enables it.
+		_, _ = w.WriteString("<button type=\"button\" class=\"quiz-check\" hidden>Check answer</button>\n")
+		_, _ = w.WriteString("<div class=\"quiz-feedback\" aria-live=\"polite\"></div>\n")
+		_, _ = w.WriteString("</fieldset>\n")
+		_, _ = w.WriteString("</div>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *quizHTMLRenderer) renderPrompt(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString("<legend class=\"quiz-question\">")
+	} else {
+		_, _ = w.WriteString("</legend>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *quizHTMLRenderer) renderChoices(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString("<ul class=\"quiz-choices\">\n")
+	} else {
+		_, _ = w.WriteString("</ul>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *quizHTMLRenderer) renderChoice(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Choice)
+	if entering {
+		inputType := "radio"
+		if n.Multiple {
+			inputType = "checkbox"
+		}
+		index := strconv.Itoa(n.Index)
+
+		_, _ = w.WriteString("<li class=\"quiz-choice\">\n")
+		_, _ = w.WriteString("<input type=\"" + inputType + "\" name=\"" + n.QuizID + "\" id=\"" + n.QuizID + "-choice-" + index + "\" value=\"" + index + "\"")
+		_, _ = w.WriteString(" data-correct=\"" + strconv.FormatBool(n.Correct) + "\"")
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			if e, ok := c.(*Explanation); ok {
+				_, _ = w.WriteString(" aria-describedby=\"" + e.ID + "\"")
+			}
+		}
+		_, _ = w.WriteString(">\n")
+	} else {
+		_, _ = w.WriteString("</li>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *quizHTMLRenderer) renderChoiceLabel(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ChoiceLabel)
+	if entering {
+		_, _ = w.WriteString("<label for=\"" + n.InputID + "\">")
+	} else {
+		_, _ = w.WriteString("</label>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *quizHTMLRenderer) renderExplanation(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Explanation)
+	if entering {
+		_, _ = w.WriteString("<div class=\"quiz-explanation\" id=\"" + n.ID + "\" hidden>\n")
+	} else {
+		_, _ = w.WriteString("</div>\n")
+	}
+	return ast.WalkContinue, nil
+}
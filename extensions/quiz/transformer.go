@@ -0,0 +1,207 @@
+package quiz
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Question is the answer key entry for a single quiz, suitable for JSON output.
+type Question struct {
+	ID       string   `json:"id"`
+	Question string   `json:"question"`
+	Multiple bool     `json:"multiple"`
+	Choices  []Answer `json:"choices"`
+	Correct  []int    `json:"correct"`
+}
+
+// Answer is a single choice in the answer key.
+type Answer struct {
+	Index       int    `json:"index"`
+	Text        string `json:"text"`
+	Correct     bool   `json:"correct"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+var answerKeyContextKey = parser.NewContextKey()
+
+// GetAnswerKey returns the answer key collected while parsing a document.
+func GetAnswerKey(pc parser.Context) []Question {
+	v := pc.Get(answerKeyContextKey)
+	if v == nil {
+		return []Question{}
+	}
+	return v.([]Question)
+}
+
+// ----- QuizTransformer
+
+// QuizTransformer turns the task list inside each quiz into choices,
+// numbers the quizzes, and records the answer key in the parser context.
+type QuizTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *QuizTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var quizzes []*Quiz
+
+	// Collect all quizzes without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+		if q, ok := node.(*Quiz); ok {
+			quizzes = append(quizzes, q)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// Nothing to do if there were no quizzes found.
+	if len(quizzes) == 0 {
+		return
+	}
+
+	source := reader.Source()
+	key := make([]Question, 0, len(quizzes))
+
+	for i, q := range quizzes {
+		q.ID = "quiz-" + strconv.Itoa(i+1)
+
+		question := Question{ID: q.ID, Question: q.Question, Choices: []Answer{}, Correct: []int{}}
+		if prompt, ok := q.FirstChild().(*Prompt); ok {
+			question.Question = strings.TrimSpace(string(prompt.Text(source)))
+		}
+
+		list := findChoiceList(q)
+		if list != nil {
+			choices := transformChoices(q, list, source)
+			for c := choices.FirstChild(); c != nil; c = c.NextSibling() {
+				choice := c.(*Choice)
+				answer := Answer{Index: choice.Index, Correct: choice.Correct}
+				for cc := choice.FirstChild(); cc != nil; cc = cc.NextSibling() {
+					switch n := cc.(type) {
+					case *ChoiceLabel:
+						answer.Text = strings.TrimSpace(string(n.Text(source)))
+					case *Explanation:
+						answer.Explanation = blockText(n, source)
+					}
+				}
+				if answer.Correct {
+					question.Correct = append(question.Correct, answer.Index)
+				}
+				question.Choices = append(question.Choices, answer)
+			}
+		}
+
+		q.Multiple = len(question.Correct) > 1
+		question.Multiple = q.Multiple
+		for c := q.FirstChild(); c != nil; c = c.NextSibling() {
+			if choices, ok := c.(*Choices); ok {
+				for cc := choices.FirstChild(); cc != nil; cc = cc.NextSibling() {
+					cc.(*Choice).Multiple = q.Multiple
+				}
+			}
+		}
+
+		key = append(key, question)
+	}
+
+	pctx.Set(answerKeyContextKey, key)
+}
+
+// findChoiceList returns the first task list directly inside the quiz.
+func findChoiceList(q *Quiz) *ast.List {
+	for c := q.FirstChild(); c != nil; c = c.NextSibling() {
+		list, ok := c.(*ast.List)
+		if !ok {
+			continue
+		}
+		for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+			if checkBox(item) != nil {
+				return list
+			}
+		}
+	}
+	return nil
+}
+
+// checkBox returns the task list checkbox that starts a list item, if any.
+func checkBox(item ast.Node) *east.TaskCheckBox {
+	text := item.FirstChild()
+	if text == nil {
+		return nil
+	}
+	if cb, ok := text.FirstChild().(*east.TaskCheckBox); ok {
+		return cb
+	}
+	return nil
+}
+
+// transformChoices replaces the task list with a Choices node.
+func transformChoices(q *Quiz, list *ast.List, source []byte) *Choices {
+	choices := &Choices{}
+	index := 1
+
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		choice := &Choice{QuizID: q.ID, Index: index}
+		inputID := q.ID + "-choice-" + strconv.Itoa(index)
+
+		if cb := checkBox(item); cb != nil {
+			choice.Correct = cb.IsChecked
+			textBlock := cb.Parent()
+			textBlock.RemoveChild(textBlock, cb)
+
+			// The checkbox leaves a space in front of the choice text.
+			if t, ok := textBlock.FirstChild().(*ast.Text); ok {
+				t.Segment = t.Segment.TrimLeftSpace(source)
+			}
+		}
+
+		labeled := false
+		for c := item.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			switch {
+			case !labeled && (c.Kind() == ast.KindTextBlock || c.Kind() == ast.KindParagraph):
+				label := &ChoiceLabel{InputID: inputID}
+				moveChildren(label, c)
+				choice.AppendChild(choice, label)
+				labeled = true
+			case c.Kind() == ast.KindBlockquote:
+				explanation := &Explanation{ID: q.ID + "-explanation-" + strconv.Itoa(index)}
+				moveChildren(explanation, c)
+				choice.AppendChild(choice, explanation)
+			default:
+				choice.AppendChild(choice, c)
+			}
+			c = next
+		}
+
+		choices.AppendChild(choices, choice)
+		index++
+	}
+
+	q.ReplaceChild(q, list, choices)
+	return choices
+}
+
+// moveChildren moves every child of src to the end of dst.
+func moveChildren(dst, src ast.Node) {
+	for c := src.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		dst.AppendChild(dst, c)
+		c = next
+	}
+}
+
+// blockText returns the plain text of each child block, separated by newlines.
+func blockText(n ast.Node, source []byte) string {
+	var parts []string
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		parts = append(parts, strings.TrimSpace(string(c.Text(source))))
+	}
+	return strings.Join(parts, "\n")
+}
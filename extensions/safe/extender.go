@@ -0,0 +1,49 @@
+package safe
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// DefaultAllowedTags are the raw HTML tags allowed when no list is given.
+var DefaultAllowedTags = []string{"abbr", "br", "del", "ins", "kbd", "mark", "small", "sub", "sup"}
+
+// DefaultAllowedAttributes are the attributes allowed when no list is given.
+var DefaultAllowedAttributes = []string{"class", "dir", "id", "lang", "title"}
+
+// Extender makes the output safe for untrusted Markdown. Raw HTML is
+// removed unless its tag is in AllowedTags, links and images with
+// dangerous URLs are turned into plain text, and attributes not in
+// AllowedAttributes are removed. Use this without html.WithUnsafe().
+// It only checks the Markdown, so extensions that read files, run
+// commands, or embed other pages have to be turned off or restricted too.
+//
+// A name ending in "*" in AllowedAttributes allows every attribute
+// that starts with it, like "data-*".
+type Extender struct {
+	AllowedTags       []string // Defaults to DefaultAllowedTags
+	AllowedAttributes []string // Defaults to DefaultAllowedAttributes
+}
+
+func (e *Extender) Extend(m goldmark.Markdown) {
+	tags := e.AllowedTags
+	if tags == nil {
+		tags = DefaultAllowedTags
+	}
+	attributes := e.AllowedAttributes
+	if attributes == nil {
+		attributes = DefaultAllowedAttributes
+	}
+	policy := newPolicy(tags, attributes)
+
+	// Runs before the other transformers so it only checks attributes
+	// from the Markdown, not the ones the extensions add.
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&SafeTransformer{policy: policy}, 50),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&RawHTMLRenderer{policy: policy}, 0),
+	))
+}
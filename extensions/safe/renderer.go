@@ -0,0 +1,139 @@
+package safe
+
+import (
+	h "html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	// tokenPattern splits HTML into comments, tags, and the text between them.
+	tokenPattern = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>?`)
+
+	tagPattern       = regexp.MustCompile(`(?s)^<(/?)([A-Za-z][A-Za-z0-9-]*)(.*?)(/?)>$`)
+	attributePattern = regexp.MustCompile(`([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+)
+
+// rawTextTags have contents that aren't text, so the contents are
+// removed along with the tags.
+var rawTextTags = map[string]bool{"script": true, "style": true, "textarea": true, "title": true, "xmp": true, "iframe": true, "noembed": true, "noframes": true, "noscript": true}
+
+// RawHTMLRenderer renders raw HTML, keeping only the tags and attributes
+// the policy allows.
+type RawHTMLRenderer struct {
+	policy *policy
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *RawHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
+}
+
+func (r *RawHTMLRenderer) renderHTMLBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.HTMLBlock)
+
+	var b strings.Builder
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		b.Write(line.Value(source))
+	}
+	if n.HasClosure() {
+		b.Write(n.ClosureLine.Value(source))
+	}
+	// Don't leave a blank line where a block was removed.
+	if html := r.policy.Sanitize(b.String()); strings.TrimSpace(html) != "" {
+		_, _ = w.WriteString(html)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *RawHTMLRenderer) renderRawHTML(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	n := node.(*ast.RawHTML)
+
+	var b strings.Builder
+	for i := 0; i < n.Segments.Len(); i++ {
+		segment := n.Segments.At(i)
+		b.Write(segment.Value(source))
+	}
+	_, _ = w.WriteString(r.policy.Sanitize(b.String()))
+	return ast.WalkSkipChildren, nil
+}
+
+// Sanitize removes the tags and attributes the policy doesn't allow from
+// a fragment of HTML. Comments are removed, and the contents of tags like
+// <script> are removed along with them.
+func (p *policy) Sanitize(html string) string {
+	var out strings.Builder
+	skipUntil := "" // the closing tag of a raw text element being removed
+
+	last := 0
+	for _, loc := range tokenPattern.FindAllStringIndex(html, -1) {
+		if skipUntil == "" {
+			out.WriteString(escapeText(html[last:loc[0]]))
+		}
+		last = loc[1]
+		token := html[loc[0]:loc[1]]
+
+		m := tagPattern.FindStringSubmatch(token)
+		if m == nil {
+			// a comment, or a "<" that isn't a tag
+			if skipUntil == "" && !strings.HasPrefix(token, "<!") {
+				out.WriteString(escapeText(token))
+			}
+			continue
+		}
+
+		closing := m[1] == "/"
+		name := strings.ToLower(m[2])
+		if skipUntil != "" {
+			if closing && name == skipUntil {
+				skipUntil = ""
+			}
+			continue
+		}
+		if !p.tags[name] {
+			if !closing && rawTextTags[name] && m[4] == "" {
+				skipUntil = name
+			}
+			continue
+		}
+
+		if closing {
+			out.WriteString("</" + name + ">")
+			continue
+		}
+		out.WriteString("<" + name)
+		for _, attr := range attributePattern.FindAllStringSubmatch(m[3], -1) {
+			value := attr[2] + attr[3] + attr[4]
+			if !p.allowsAttribute(attr[1], h.UnescapeString(value)) {
+				continue
+			}
+			out.WriteString(" " + strings.ToLower(attr[1]) + "=\"" + h.EscapeString(h.UnescapeString(value)) + "\"")
+		}
+		if m[4] == "/" {
+			out.WriteString(" /")
+		}
+		out.WriteString(">")
+	}
+	if skipUntil == "" {
+		out.WriteString(escapeText(html[last:]))
+	}
+	return out.String()
+}
+
+// escapeText escapes the angle brackets in text between tags.
+// Entities like &amp; are left alone.
+func escapeText(text string) string {
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(text)
+}
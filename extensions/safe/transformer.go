@@ -0,0 +1,182 @@
+package safe
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// safeSchemes are the URL schemes links may use. URLs without a scheme are relative and always safe.
+var safeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+	"tel":    true,
+	"ftp":    true,
+}
+
+// safeDataImages are the data: URL types images may use.
+var safeDataImages = []string{"data:image/png", "data:image/gif", "data:image/jpeg", "data:image/webp"}
+
+// urlAttributes hold URLs, so their values are checked like link destinations.
+var urlAttributes = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"cite":       true,
+	"action":     true,
+	"formaction": true,
+	"poster":     true,
+	"background": true,
+	"xlink:href": true,
+}
+
+// policy is the list of allowed tags and attributes.
+type policy struct {
+	tags       map[string]bool
+	attributes map[string]bool
+	prefixes   []string // from names like "data-*"
+}
+
+func newPolicy(tags, attributes []string) *policy {
+	p := &policy{tags: map[string]bool{}, attributes: map[string]bool{}}
+	for _, tag := range tags {
+		p.tags[strings.ToLower(tag)] = true
+	}
+	for _, name := range attributes {
+		name = strings.ToLower(name)
+		if strings.HasSuffix(name, "*") {
+			p.prefixes = append(p.prefixes, strings.TrimSuffix(name, "*"))
+			continue
+		}
+		p.attributes[name] = true
+	}
+	return p
+}
+
+// allowsAttribute reports whether an attribute and its value are allowed.
+// Event handlers like onclick are never allowed.
+func (p *policy) allowsAttribute(name, value string) bool {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, "on") {
+		return false
+	}
+	if urlAttributes[name] && !IsSafeURL(value, false) {
+		return false
+	}
+	if p.attributes[name] {
+		return true
+	}
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSafeURL reports whether a URL can be used in a link, or in an image if
+// image is true. Relative URLs are safe. The check ignores case and the
+// whitespace and control characters browsers skip, so "JavaScript:" and
+// "java\tscript:" are caught too.
+func IsSafeURL(url string, image bool) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.ToLower(url))
+
+	colon := strings.IndexByte(cleaned, ':')
+	if colon < 0 || strings.ContainsAny(cleaned[:colon], "/?#") {
+		return true
+	}
+	scheme := cleaned[:colon]
+	if safeSchemes[scheme] {
+		return true
+	}
+	if image && scheme == "data" {
+		for _, prefix := range safeDataImages {
+			if strings.HasPrefix(cleaned, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SafeTransformer turns links and images with dangerous URLs into their
+// text and removes attributes the policy doesn't allow.
+type SafeTransformer struct {
+	policy *policy
+}
+
+// Transform checks every node.
+func (t *SafeTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var unsafe []ast.Node
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := node.(type) {
+		case *ast.Link:
+			if !IsSafeURL(string(n.Destination), false) {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.Image:
+			if !IsSafeURL(string(n.Destination), true) {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.AutoLink:
+			if !IsSafeURL(string(n.URL(source)), false) {
+				unsafe = append(unsafe, n)
+			}
+		}
+
+		t.removeAttributes(node)
+		return ast.WalkContinue, nil
+	})
+
+	for _, node := range unsafe {
+		parent := node.Parent()
+		if parent == nil {
+			continue
+		}
+		if autoLink, ok := node.(*ast.AutoLink); ok {
+			parent.ReplaceChild(parent, node, ast.NewString(autoLink.Label(source)))
+			continue
+		}
+		// Keep the link text or image description.
+		for child := node.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			parent.InsertBefore(parent, node, child)
+			child = next
+		}
+		parent.RemoveChild(parent, node)
+	}
+}
+
+// removeAttributes removes the attributes the policy doesn't allow.
+func (t *SafeTransformer) removeAttributes(node ast.Node) {
+	attributes := node.Attributes()
+	if len(attributes) == 0 {
+		return
+	}
+	node.RemoveAttributes()
+	for _, attr := range attributes {
+		value, ok := attr.Value.([]byte)
+		if !ok {
+			if s, isString := attr.Value.(string); isString {
+				value = []byte(s)
+			}
+		}
+		if t.policy.allowsAttribute(string(attr.Name), string(bytes.TrimSpace(value))) {
+			node.SetAttribute(attr.Name, attr.Value)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package steps
+
+import (
+	"lessonmd/extensions/internal/syntax"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// StepsKind is the NodeKind for Steps
+var StepsKind = ast.NewNodeKind("Steps")
+
+// Steps is a numbered procedure. After the transformer runs, its
+// children are Step nodes.
+type Steps struct {
+	ast.BaseBlock
+	Start    int  // Number of the first step. 0 means continue or start at 1.
+	Continue bool // Continue numbering from the previous steps block
+	lines    syntax.BlockLines
+}
+
+// NewSteps returns a new Steps node.
+func NewSteps(start int, cont bool) *Steps {
+	return &Steps{
+		Start:     start,
+		Continue:  cont,
+		BaseBlock: ast.BaseBlock{},
+	}
+}
+
+// Kind returns the kind of this node
+func (s *Steps) Kind() ast.NodeKind {
+	return StepsKind
+}
+
+// Dump dumps the Steps node to stdout
+func (s *Steps) Dump(source []byte, level int) {
+	ast.DumpHelper(s, source, level, map[string]string{
+		"Start":    strconv.Itoa(s.Start),
+		"Continue": strconv.FormatBool(s.Continue),
+	}, nil)
+}
+
+// StepKind is the NodeKind for Step
+var StepKind = ast.NewNodeKind("Step")
+
+// Step is a single numbered step. Its first child is the StepHeading.
+type Step struct {
+	ast.BaseBlock
+	Number int
+	ID     string
+}
+
+// Kind returns the kind of this node
+func (s *Step) Kind() ast.NodeKind {
+	return StepKind
+}
+
+// Dump dumps the Step node to stdout
+func (s *Step) Dump(source []byte, level int) {
+	ast.DumpHelper(s, source, level, map[string]string{
+		"Number": strconv.Itoa(s.Number),
+		"ID":     s.ID,
+	}, nil)
+}
+
+// PrefixIDs adds prefix to the id of the step.
+func (s *Step) PrefixIDs(prefix string) {
+	s.ID = prefix + s.ID
+}
+
+// AnchorID returns the id of the step, for links to it.
+func (s *Step) AnchorID() string {
+	return s.ID
+}
+
+// StepHeadingKind is the NodeKind for StepHeading
+var StepHeadingKind = ast.NewNodeKind("StepHeading")
+
+// StepHeading holds the inline title of a step.
+type StepHeading struct {
+	ast.BaseBlock
+	Number int
+	StepID string
+}
+
+// Kind returns the kind of this node
+func (s *StepHeading) Kind() ast.NodeKind {
+	return StepHeadingKind
+}
+
+// Dump dumps the StepHeading node to stdout
+func (s *StepHeading) Dump(source []byte, level int) {
+	ast.DumpHelper(s, source, level, nil, nil)
+}
+
+// PrefixIDs adds prefix to the id of the step the heading links to.
+func (s *StepHeading) PrefixIDs(prefix string) {
+	s.StepID = prefix + s.StepID
+}
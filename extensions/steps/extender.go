@@ -0,0 +1,28 @@
+package steps
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type stepsExtender struct{}
+
+// StepsExtender is the steps extension
+var StepsExtender = &stepsExtender{}
+
+// Extend adds the steps parser, transformer, and renderer.
+func (e *stepsExtender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(&stepsParser{}, 100),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(&StepsTransformer{}, 100),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&stepsHTMLRenderer{}, 0),
+	))
+}
@@ -0,0 +1,75 @@
+package steps
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+type stepsParser struct {
+}
+
+var defaultStepsParser = &stepsParser{}
+
+// NewStepsParser returns a new BlockParser for steps
+func NewStepsParser() parser.BlockParser {
+	return defaultStepsParser
+}
+
+func (s *stepsParser) Trigger() []byte {
+	return []byte("[steps")
+}
+
+func (s *stepsParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+
+	if !bytes.HasPrefix(line, []byte("[steps")) {
+		return nil, parser.NoChildren
+	}
+
+	// Get the options after "[steps", e.g. "continue" or "start=5"
+	start := 0
+	cont := false
+	for _, opt := range bytes.Fields(bytes.TrimPrefix(line, []byte("[steps"))) {
+		if bytes.Equal(opt, []byte("continue")) {
+			cont = true
+		} else if bytes.HasPrefix(opt, []byte("start=")) {
+			if n, err := strconv.Atoi(string(bytes.TrimPrefix(opt, []byte("start=")))); err == nil {
+				start = n
+			}
+		} else {
+			return nil, parser.NoChildren
+		}
+	}
+
+	return NewSteps(start, cont), parser.NoChildren
+}
+
+func (s *stepsParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, _ := reader.PeekLine()
+
+	// The block ends at its own "]", not at one in a code block or one
+	// that closes a block inside it, like a [details block in a step.
+	// Leave the line ending so the next line isn't read as a lazy
+	// continuation of a paragraph.
+	if node.(*Steps).lines.Closes(line) {
+		reader.Advance(len(bytes.TrimRight(line, "\r\n")))
+		return parser.Close
+	}
+
+	return parser.Continue | parser.HasChildren
+}
+
+func (s *stepsParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+}
+
+func (s *stepsParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (s *stepsParser) CanAcceptIndentedLine() bool {
+	return false
+}
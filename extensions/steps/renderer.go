@@ -0,0 +1,59 @@
+package steps
+
+import (
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type stepsHTMLRenderer struct {
+}
+
+// NewStepsHTMLRenderer returns a new renderer for steps nodes.
+func NewStepsHTMLRenderer() renderer.NodeRenderer {
+	return &stepsHTMLRenderer{}
+}
+
+func (r *stepsHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(StepsKind, r.renderSteps)
+	reg.Register(StepKind, r.renderStep)
+	reg.Register(StepHeadingKind, r.renderStepHeading)
+}
+
+func (r *stepsHTMLRenderer) renderSteps(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Steps)
+	if entering {
+		_, _ = w.WriteString("<ol class=\"steps\"")
+		if n.Start != 1 {
+			_, _ = w.WriteString(" start=\"" + strconv.Itoa(n.Start) + "\"")
+		}
+		_, _ = w.WriteString(">\n")
+	} else {
+		_, _ = w.WriteString("</ol>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *stepsHTMLRenderer) renderStep(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Step)
+	if entering {
+		_, _ = w.WriteString("<li class=\"step\" id=\"" + n.ID + "\" value=\"" + strconv.Itoa(n.Number) + "\">\n")
+	} else {
+		_, _ = w.WriteString("</li>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *stepsHTMLRenderer) renderStepHeading(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*StepHeading)
+	if entering {
+		number := strconv.Itoa(n.Number)
+		_, _ = w.WriteString("<div class=\"step-heading\">")
+		_, _ = w.WriteString("<a class=\"step-number\" href=\"#" + n.StepID + "\" aria-label=\"Step " + number + "\">" + number + "</a> ")
+	} else {
+		_, _ = w.WriteString("</div>\n")
+	}
+	return ast.WalkContinue, nil
+}
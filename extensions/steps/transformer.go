@@ -0,0 +1,157 @@
+package steps
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- StepsTransformer
+
+// StepsTransformer splits each steps block into numbered Step nodes at
+// every "## Step" heading or "Step:" marker.
+type StepsTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *StepsTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var blocks []*Steps
+
+	// Collect all steps blocks without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+		if n, ok := node.(*Steps); ok {
+			blocks = append(blocks, n)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	source := reader.Source()
+	last := 0
+	prefix := "step-"
+
+	for i, block := range blocks {
+		number := 1
+		switch {
+		case block.Start > 0:
+			number = block.Start
+		case block.Continue:
+			number = last + 1
+		}
+
+		// A later block that starts over needs its own ids. A block that
+		// continues keeps the ids of the block it continues, so its step
+		// numbers carry on from there without repeating an id.
+		if !block.Continue && i > 0 {
+			prefix = "steps-" + strconv.Itoa(i+1) + "-step-"
+		}
+		block.Start = number
+
+		var step *Step
+		for c := block.FirstChild(); c != nil; {
+			next := c.NextSibling()
+
+			if isMarker(c, source) {
+				step = &Step{Number: number, ID: prefix + strconv.Itoa(number)}
+				heading := &StepHeading{Number: number, StepID: step.ID}
+				moveTitle(heading, c, source)
+				step.AppendChild(step, heading)
+				block.InsertBefore(block, c, step)
+				if c.ChildCount() == 0 {
+					block.RemoveChild(block, c)
+				} else {
+					step.AppendChild(step, c)
+				}
+				number++
+			} else if step != nil {
+				step.AppendChild(step, c)
+			} else if parent := block.Parent(); parent != nil {
+				// Content before the first step introduces the procedure.
+				parent.InsertBefore(parent, block, c)
+			}
+
+			c = next
+		}
+
+		last = number - 1
+	}
+}
+
+// isMarker reports whether the node starts a new step: a heading that
+// begins with "Step" or a paragraph that begins with "Step:".
+func isMarker(n ast.Node, source []byte) bool {
+	t, ok := n.FirstChild().(*ast.Text)
+	if !ok {
+		return false
+	}
+	value := t.Segment.Value(source)
+
+	switch n.Kind() {
+	case ast.KindHeading:
+		if !bytes.HasPrefix(value, []byte("Step")) {
+			return false
+		}
+		rest := value[len("Step"):]
+		return len(rest) == 0 || rest[0] == ' ' || rest[0] == ':'
+	case ast.KindParagraph:
+		return bytes.HasPrefix(value, []byte("Step:"))
+	}
+	return false
+}
+
+// moveTitle moves the title of a marker into the heading and strips the
+// "Step", optional number, and colon in front of it. Headings give up all
+// of their children; paragraphs give up their first line.
+func moveTitle(heading *StepHeading, marker ast.Node, source []byte) {
+	trimPrefix(marker, source)
+
+	for c := marker.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		endOfLine := false
+		if text, ok := c.(*ast.Text); ok && marker.Kind() == ast.KindParagraph {
+			endOfLine = text.SoftLineBreak() || text.HardLineBreak()
+			text.SetSoftLineBreak(false)
+			text.SetHardLineBreak(false)
+		}
+		heading.AppendChild(heading, c)
+		if endOfLine {
+			break
+		}
+		c = next
+	}
+}
+
+// trimPrefix removes "Step", an optional number, and an optional colon
+// from the start of the marker. The inline parser may have split these
+// across several text nodes.
+func trimPrefix(marker ast.Node, source []byte) {
+	t := marker.FirstChild().(*ast.Text)
+	t.Segment = t.Segment.WithStart(t.Segment.Start + len("Step"))
+
+	colon := false
+	for t != nil {
+		value := t.Segment.Value(source)
+		i := 0
+		for ; i < len(value); i++ {
+			b := value[i]
+			if b == ':' && !colon {
+				colon = true
+			} else if b != ' ' && !(b >= '0' && b <= '9' && !colon) {
+				break
+			}
+		}
+		t.Segment = t.Segment.WithStart(t.Segment.Start + i)
+		if i < len(value) || t.SoftLineBreak() || t.HardLineBreak() {
+			return
+		}
+
+		next, _ := t.NextSibling().(*ast.Text)
+		marker.RemoveChild(marker, t)
+		t = next
+	}
+}
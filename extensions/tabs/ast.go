@@ -1,13 +1,19 @@
 package tabs
 
 import (
+	"strconv"
+
 	"github.com/yuin/goldmark/ast"
 )
 
-// TabGroup represents the entire tab group container
+// TabGroup holds a run of consecutive tabs. The transformer creates it
+// around the Tab nodes the parser finds.
 type TabGroup struct {
 	ast.BaseBlock
-	tabCounter int // Counter for generating unique IDs
+	ID      string // Assigned by the transformer, e.g. tabs-1
+	Title   string // Text of the heading the group comes after, if any
+	SyncKey string // Names of the tabs, so groups with the same tabs can be switched together
+	Static  bool   // Render with radio inputs so the tabs work without JavaScript
 }
 
 // KindTabGroup is the NodeKind for TabGroup
@@ -20,13 +26,34 @@ func (n *TabGroup) Kind() ast.NodeKind {
 
 // Dump dumps the TabGroup node to stdout
 func (n *TabGroup) Dump(source []byte, level int) {
-	ast.DumpHelper(n, source, level, nil, nil)
+	ast.DumpHelper(n, source, level, map[string]string{
+		"ID":      n.ID,
+		"Title":   n.Title,
+		"SyncKey": n.SyncKey,
+		"Static":  strconv.FormatBool(n.Static),
+	}, nil)
+}
+
+// PrefixIDs adds prefix to the id of the group.
+func (n *TabGroup) PrefixIDs(prefix string) {
+	n.ID = prefix + n.ID
+}
+
+// AnchorID returns the id of the group, for links to it.
+func (n *TabGroup) AnchorID() string {
+	return n.ID
 }
 
 // Tab represents a single tab with title and content
 type Tab struct {
 	ast.BaseBlock
-	Title []byte // Tab title from === "Title"
+	Title    []byte // Tab title from === "Title"
+	Name     string // Normalized title, used to switch tabs with the same name together
+	Default  bool   // Selected when the page loads, from === "Title" default
+	Selected bool   // Assigned by the transformer: the default tab, or else the first
+	ID       string // Assigned by the transformer, e.g. tab-1-2
+	PanelID  string // Assigned by the transformer, e.g. tab-panel-1-2
+	indent   int    // Columns of indentation the parser removes from the content
 }
 
 // KindTab is the NodeKind for Tab
@@ -40,6 +67,15 @@ func (n *Tab) Kind() ast.NodeKind {
 // Dump dumps the Tab node to stdout
 func (n *Tab) Dump(source []byte, level int) {
 	ast.DumpHelper(n, source, level, map[string]string{
-		"Title": string(n.Title),
+		"Title":    string(n.Title),
+		"Default":  strconv.FormatBool(n.Default),
+		"Selected": strconv.FormatBool(n.Selected),
+		"ID":       n.ID,
 	}, nil)
-}
\ No newline at end of file
+}
+
+// PrefixIDs adds prefix to the ids of the tab and its panel.
+func (n *Tab) PrefixIDs(prefix string) {
+	n.ID = prefix + n.ID
+	n.PanelID = prefix + n.PanelID
+}
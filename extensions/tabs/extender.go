@@ -7,17 +7,30 @@ import (
 	"github.com/yuin/goldmark/util"
 )
 
-type tabsExtender struct{}
+// Extender is the tabs extension.
+// Print renders every panel one after another, for printing.
+// Static renders tabs with radio inputs that work without JavaScript.
+type Extender struct {
+	Print  bool
+	Static bool
+}
 
 // TabsExtender is the tabs extension
-var TabsExtender = &tabsExtender{}
+var TabsExtender = &Extender{}
 
 // Extend extends the Goldmark parser with tabs functionality
-func (e *tabsExtender) Extend(m goldmark.Markdown) {
-	m.Parser().AddOptions(parser.WithBlockParsers(
-		util.Prioritized(NewTabsParser(), 100),
-	))
+func (e *Extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(NewTabsParser(), 100),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(&TabsTransformer{Static: e.Static}, 100),
+		),
+	)
+	r := NewTabGroupHTMLRenderer().(*TabGroupHTMLRenderer)
+	r.Print = e.Print
 	m.Renderer().AddOptions(renderer.WithNodeRenderers(
-		util.Prioritized(NewTabGroupHTMLRenderer(), 0),
+		util.Prioritized(r, 0),
 	))
 }
\ No newline at end of file
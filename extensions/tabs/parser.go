@@ -26,7 +26,7 @@ func (p *tabsParser) Trigger() []byte {
 
 // Open parses the beginning of a tab block
 func (p *tabsParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
-	line, segment := reader.PeekLine()
+	line, _ := reader.PeekLine()
 	
 	// Check for === "Title" pattern
 	if !bytes.HasPrefix(line, []byte("=== \"")) {
@@ -37,9 +37,11 @@ func (p *tabsParser) Open(parent ast.Node, reader text.Reader, pc parser.Context
 	title := extractTabTitle(line)
 	
 	// Create new Tab node - renderer will handle grouping
-	tab := &Tab{Title: title}
+	tab := &Tab{Title: title, Default: isDefaultTab(line)}
 	
-	reader.Advance(segment.Len())
+	// Leave the newline, so the first line of content goes through Continue
+	// and has its indentation removed like the rest
+	reader.Advance(len(bytes.TrimRight(line, "\r\n")))
 	return tab, parser.HasChildren
 }
 
@@ -59,24 +61,33 @@ func (p *tabsParser) Continue(node ast.Node, reader text.Reader, pc parser.Conte
 		return parser.Continue | parser.HasChildren
 	}
 	
-	// Check indentation level - content must be indented by at least 2 spaces to stay in tab
-	indentLevel := getIndentationLevel(line)
+	// Check indentation level - content must be indented by at least 2 columns to stay in tab.
+	// A tab character advances to the next multiple of 4 columns.
+	indentLevel, _ := util.IndentWidth(line, reader.LineOffset())
 	if indentLevel < 2 {
 		// Content has returned to root level (less than 2 spaces), close this tab
 		return parser.Close
 	}
 	
-	// Remove the first 2 spaces from the line to normalize indentation
-	// This is done by advancing the segment start position
-	spacesToRemove := 0
-	for i := 0; i < len(line) && i < 2 && line[i] == ' '; i++ {
-		spacesToRemove++
+	// The first line of content sets the indentation for the tab: 2 or 3
+	// columns, or 4 as in MkDocs Material. Anything deeper belongs to the
+	// content, like an indented code block or a nested tab group.
+	tab := node.(*Tab)
+	if tab.indent == 0 {
+		tab.indent = indentLevel
+		if tab.indent > 4 {
+			tab.indent = 4
+		}
 	}
 	
-	if spacesToRemove > 0 {
-		// Adjust the reader to skip the leading spaces
-		reader.Advance(spacesToRemove)
+	// Remove the tab's indentation from the line, keeping the padding
+	// left over when a tab character spans it
+	width := tab.indent
+	if indentLevel < width {
+		width = indentLevel
 	}
+	pos, padding := util.IndentPosition(line, reader.LineOffset(), width)
+	reader.AdvanceAndSetPadding(pos, padding)
 	
 	return parser.Continue | parser.HasChildren
 }
@@ -97,26 +108,6 @@ func (p *tabsParser) CanAcceptIndentedLine() bool {
 }
 
 
-// getIndentationLevel returns the number of leading spaces in a line
-func getIndentationLevel(line []byte) int {
-	count := 0
-	for i, b := range line {
-		if b == ' ' {
-			count++
-		} else if b == '\t' {
-			// Count tabs as 4 spaces for consistency
-			count += 4
-		} else {
-			break
-		}
-		// Don't count beyond the line length
-		if i >= len(line)-1 {
-			break
-		}
-	}
-	return count
-}
-
 // extractTabTitle extracts the title from a === "Title" line
 func extractTabTitle(line []byte) []byte {
 	// Find content between === " and closing "
@@ -132,4 +123,13 @@ func extractTabTitle(line []byte) []byte {
 	}
 	
 	return util.TrimRightSpace(line[start:end])
-}
\ No newline at end of file
+}
+
+// isDefaultTab reports whether a === "Title" line ends with "default"
+func isDefaultTab(line []byte) bool {
+	end := bytes.LastIndex(line, []byte("\""))
+	if end == -1 {
+		return false
+	}
+	return string(bytes.TrimSpace(line[end+1:])) == "default"
+}
@@ -1,7 +1,6 @@
 package tabs
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -14,7 +13,7 @@ import (
 // TabGroupHTMLRenderer renders TabGroup nodes to HTML
 type TabGroupHTMLRenderer struct {
 	html.Config
-	tabGroupCounter int
+	Print bool // Show every panel in turn, titled with a heading, instead of tabs
 }
 
 // NewTabGroupHTMLRenderer returns a new TabGroupHTMLRenderer
@@ -50,200 +49,146 @@ func (r *TabGroupHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegist
 }
 
 // renderTabGroup renders a TabGroup node
-func (r *TabGroupHTMLRenderer) renderTabGroup(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
-	if entering {
-		r.tabGroupCounter++
-		tabGroupID := "tabs-" + strconv.Itoa(r.tabGroupCounter)
-		
-		_, _ = w.WriteString("<div class=\"tabs\" id=\"")
-		_, _ = w.WriteString(tabGroupID)
-		_, _ = w.WriteString("\">\n")
-		
-		
-		// Render tab navigation
-		_, _ = w.WriteString("  <div class=\"tabs-nav\" role=\"tablist\">\n")
-		
-		tabIndex := 1
-		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
-			if tab, ok := child.(*Tab); ok {
-				tabID := fmt.Sprintf("tab-%d-%d", r.tabGroupCounter, tabIndex)
-				panelID := fmt.Sprintf("tab-panel-%d-%d", r.tabGroupCounter, tabIndex)
-				activeClass := ""
-				ariaSelected := "false"
-				
-				if tabIndex == 1 {
-					activeClass = " active"
-					ariaSelected = "true"
-				}
-				
-				_, _ = w.WriteString("    <button class=\"tab-button")
-				_, _ = w.WriteString(activeClass)
-				_, _ = w.WriteString("\" role=\"tab\" aria-selected=\"")
-				_, _ = w.WriteString(ariaSelected)
-				_, _ = w.WriteString("\" aria-controls=\"")
-				_, _ = w.WriteString(panelID)
-				_, _ = w.WriteString("\" id=\"")
-				_, _ = w.WriteString(tabID)
-				_, _ = w.WriteString("\" data-tab-name=\"")
-				_, _ = w.WriteString(normalizeTabName(tab.Title))
-				_, _ = w.WriteString("\">")
-				_, _ = w.Write(util.EscapeHTML(tab.Title))
-				_, _ = w.WriteString("</button>\n")
-				
-				tabIndex++
-			}
-		}
-		
-		_, _ = w.WriteString("  </div>\n")
-		_, _ = w.WriteString("  <div class=\"tab-panels\">\n")
-	} else {
-		_, _ = w.WriteString("  </div>\n")
-		_, _ = w.WriteString("</div>\n")
-	}
-	
-	return ast.WalkContinue, nil
-}
-
-// renderTab renders a Tab node
-func (r *TabGroupHTMLRenderer) renderTab(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
-	if entering {
-		// Check if this is the first tab in a sequence of consecutive tabs
-		if r.isFirstTabInSequence(n) {
-			// Start tab group HTML
-			r.tabGroupCounter++
-			r.renderTabGroupStart(w, n)
-		}
-		
-		// Render individual tab panel
-		r.renderTabPanel(w, n, entering)
-	} else {
-		r.renderTabPanel(w, n, entering)
-		
-		// Check if this is the last tab in sequence
-		if r.isLastTabInSequence(n) {
-			// End tab group HTML
-			r.renderTabGroupEnd(w)
+func (r *TabGroupHTMLRenderer) renderTabGroup(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*TabGroup)
+	switch {
+	case r.Print:
+		if entering {
+			_, _ = w.WriteString("<div class=\"tabs tabs-print\" id=\"" + n.ID + "\">\n")
+		} else {
+			_, _ = w.WriteString("</div>\n")
 		}
-	}
-	
-	return ast.WalkContinue, nil
-}
 
-// isFirstTabInSequence checks if this tab is the first in a sequence of consecutive tabs
-func (r *TabGroupHTMLRenderer) isFirstTabInSequence(n ast.Node) bool {
-	// Check if previous sibling is also a Tab
-	if prev := n.PreviousSibling(); prev != nil {
-		if _, ok := prev.(*Tab); ok {
-			return false // Not first, previous is also a tab
+	case n.Static:
+		if entering {
+			_, _ = w.WriteString("<div class=\"tabs tabs-static\" id=\"" + n.ID + "\">\n")
+		} else {
+			_, _ = w.WriteString("</div>\n")
 		}
-	}
-	return true // Either no previous sibling or previous is not a tab
-}
 
-// isLastTabInSequence checks if this tab is the last in a sequence of consecutive tabs
-func (r *TabGroupHTMLRenderer) isLastTabInSequence(n ast.Node) bool {
-	// Check if next sibling is also a Tab
-	if next := n.NextSibling(); next != nil {
-		if _, ok := next.(*Tab); ok {
-			return false // Not last, next is also a tab
-		}
-	}
-	return true // Either no next sibling or next is not a tab
-}
+	case entering:
+		_, _ = w.WriteString("<div class=\"tabs\" id=\"" + n.ID + "\">\n")
 
-// renderTabGroupStart renders the beginning of a tab group
-func (r *TabGroupHTMLRenderer) renderTabGroupStart(w util.BufWriter, firstTab ast.Node) {
-	tabGroupID := "tabs-" + strconv.Itoa(r.tabGroupCounter)
-	
-	_, _ = w.WriteString("<div class=\"tabs\" id=\"")
-	_, _ = w.WriteString(tabGroupID)
-	_, _ = w.WriteString("\">\n")
-	
-	// Render tab navigation by collecting all consecutive tabs
-	_, _ = w.WriteString("  <div class=\"tabs-nav\" role=\"tablist\">\n")
-	
-	tabIndex := 1
-	for tab := firstTab; tab != nil; tab = tab.NextSibling() {
-		if tabNode, ok := tab.(*Tab); ok {
-			tabID := fmt.Sprintf("tab-%d-%d", r.tabGroupCounter, tabIndex)
-			panelID := fmt.Sprintf("tab-panel-%d-%d", r.tabGroupCounter, tabIndex)
+		// Render tab navigation
+		_, _ = w.WriteString("  <div class=\"tabs-nav\" role=\"tablist\">\n")
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			tab, ok := child.(*Tab)
+			if !ok {
+				continue
+			}
 			activeClass := ""
 			ariaSelected := "false"
-			
-			if tabIndex == 1 {
+			if tab.Selected {
 				activeClass = " active"
 				ariaSelected = "true"
 			}
-			
+
 			_, _ = w.WriteString("    <button class=\"tab-button")
 			_, _ = w.WriteString(activeClass)
 			_, _ = w.WriteString("\" role=\"tab\" aria-selected=\"")
 			_, _ = w.WriteString(ariaSelected)
 			_, _ = w.WriteString("\" aria-controls=\"")
-			_, _ = w.WriteString(panelID)
+			_, _ = w.WriteString(tab.PanelID)
 			_, _ = w.WriteString("\" id=\"")
-			_, _ = w.WriteString(tabID)
+			_, _ = w.WriteString(tab.ID)
 			_, _ = w.WriteString("\" data-tab-name=\"")
-			_, _ = w.WriteString(normalizeTabName(tabNode.Title))
+			_, _ = w.WriteString(tab.Name)
 			_, _ = w.WriteString("\">")
-			_, _ = w.Write(util.EscapeHTML(tabNode.Title))
+			_, _ = w.Write(util.EscapeHTML(tab.Title))
 			_, _ = w.WriteString("</button>\n")
-			
-			tabIndex++
-		} else {
-			break // Stop when we hit a non-tab
 		}
+		_, _ = w.WriteString("  </div>\n")
+		_, _ = w.WriteString("  <div class=\"tab-panels\">\n")
+
+	default:
+		_, _ = w.WriteString("  </div>\n")
+		_, _ = w.WriteString("</div>\n")
 	}
-	
-	_, _ = w.WriteString("  </div>\n")
-	_, _ = w.WriteString("  <div class=\"tab-panels\">\n")
-}
 
-// renderTabGroupEnd renders the end of a tab group
-func (r *TabGroupHTMLRenderer) renderTabGroupEnd(w util.BufWriter) {
-	_, _ = w.WriteString("  </div>\n")
-	_, _ = w.WriteString("</div>\n")
+	return ast.WalkContinue, nil
 }
 
-// renderTabPanel renders an individual tab panel
-func (r *TabGroupHTMLRenderer) renderTabPanel(w util.BufWriter, n ast.Node, entering bool) {
-	if entering {
-		// Find tab index by counting tabs from first tab in sequence
-		firstTab := n
-		for prev := n.PreviousSibling(); prev != nil; prev = prev.PreviousSibling() {
-			if _, ok := prev.(*Tab); ok {
-				firstTab = prev
-			} else {
-				break
-			}
-		}
-		
-		tabIndex := 1
-		for tab := firstTab; tab != nil && tab != n; tab = tab.NextSibling() {
-			if _, ok := tab.(*Tab); ok {
-				tabIndex++
-			}
-		}
-		
-		tabID := fmt.Sprintf("tab-%d-%d", r.tabGroupCounter, tabIndex)
-		panelID := fmt.Sprintf("tab-panel-%d-%d", r.tabGroupCounter, tabIndex)
+// renderTab renders a Tab node as a panel of its group
+func (r *TabGroupHTMLRenderer) renderTab(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Tab)
+	group, _ := n.Parent().(*TabGroup)
+	switch {
+	case r.Print:
+		r.renderPrintTab(w, n, entering)
+	case group != nil && group.Static:
+		r.renderStaticTab(w, n, group, entering)
+	case entering:
 		activeClass := ""
-		
-		if tabIndex == 1 {
+		if n.Selected {
 			activeClass = " active"
 		}
-		
-		tab := n.(*Tab)
+
 		_, _ = w.WriteString("    <div class=\"tab-panel")
 		_, _ = w.WriteString(activeClass)
 		_, _ = w.WriteString("\" role=\"tabpanel\" aria-labelledby=\"")
-		_, _ = w.WriteString(tabID)
+		_, _ = w.WriteString(n.ID)
 		_, _ = w.WriteString("\" id=\"")
-		_, _ = w.WriteString(panelID)
+		_, _ = w.WriteString(n.PanelID)
 		_, _ = w.WriteString("\" data-tab-name=\"")
-		_, _ = w.WriteString(normalizeTabName(tab.Title))
+		_, _ = w.WriteString(n.Name)
 		_, _ = w.WriteString("\">\n")
-	} else {
+	default:
 		_, _ = w.WriteString("    </div>\n")
 	}
-}
\ No newline at end of file
+
+	return ast.WalkContinue, nil
+}
+
+// renderPrintTab renders a Tab node for print, where there's nothing to
+// click: the panels follow one another, each under a heading with its title.
+func (r *TabGroupHTMLRenderer) renderPrintTab(w util.BufWriter, n *Tab, entering bool) {
+	if entering {
+		level := strconv.Itoa(printHeadingLevel(n))
+		_, _ = w.WriteString("  <div class=\"tab-panel\" id=\"" + n.PanelID + "\" data-tab-name=\"" + n.Name + "\">\n")
+		_, _ = w.WriteString("    <h" + level + " class=\"tab-title\">")
+		_, _ = w.Write(util.EscapeHTML(n.Title))
+		_, _ = w.WriteString("</h" + level + ">\n")
+	} else {
+		_, _ = w.WriteString("  </div>\n")
+	}
+}
+
+// renderStaticTab renders a Tab node as a radio input, its label, and its
+// panel. The stylesheet shows the panel after the checked input, so the
+// tabs work where scripts are stripped, like email and RSS readers.
+func (r *TabGroupHTMLRenderer) renderStaticTab(w util.BufWriter, n *Tab, group *TabGroup, entering bool) {
+	if entering {
+		checked := ""
+		if n.Selected {
+			checked = " checked"
+		}
+
+		_, _ = w.WriteString("  <input type=\"radio\" class=\"tab-input\" name=\"" + group.ID + "\" id=\"" + n.ID + "\" aria-controls=\"" + n.PanelID + "\"" + checked + ">\n")
+		_, _ = w.WriteString("  <label class=\"tab-label\" for=\"" + n.ID + "\" data-tab-name=\"" + n.Name + "\">")
+		_, _ = w.Write(util.EscapeHTML(n.Title))
+		_, _ = w.WriteString("</label>\n")
+		_, _ = w.WriteString("  <div class=\"tab-panel\" id=\"" + n.PanelID + "\" data-tab-name=\"" + n.Name + "\">\n")
+	} else {
+		_, _ = w.WriteString("  </div>\n")
+	}
+}
+
+// printHeadingLevel returns the level for tab titles in print mode: one
+// below the heading the tabs come after, or 3 if there isn't one, and one
+// more for each tab group the tabs are nested in.
+func printHeadingLevel(n ast.Node) int {
+	level := 2
+	for p := n; p != nil; p = p.Parent() {
+		if _, ok := p.(*TabGroup); ok {
+			level++
+		}
+		if heading := previousHeading(p); heading != nil {
+			level += heading.Level - 2
+			break
+		}
+	}
+	if level > 6 {
+		return 6
+	}
+	return level
+}
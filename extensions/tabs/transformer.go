@@ -0,0 +1,115 @@
+package tabs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	meta "github.com/yuin/goldmark-meta"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- TabsTransformer
+
+// TabsTransformer wraps each run of consecutive tabs in a TabGroup,
+// numbers the groups and tabs, and decides how they're rendered. Tabs are
+// static when Static is set, unless the document's front matter overrides
+// it with "tabs: static" or "tabs: script".
+type TabsTransformer struct {
+	Static bool
+}
+
+// Transform converts the nodes.
+func (t *TabsTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	static := t.Static
+	switch fmt.Sprint(meta.Get(pc)["tabs"]) {
+	case "static":
+		static = true
+	case "script":
+		static = false
+	}
+
+	// Collect the first tab of every run without modifying the tree.
+	var firstTabs []*Tab
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if tab, ok := n.(*Tab); ok && entering {
+			if _, ok := n.PreviousSibling().(*Tab); !ok {
+				firstTabs = append(firstTabs, tab)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for i, first := range firstTabs {
+		number := strconv.Itoa(i + 1)
+		group := &TabGroup{
+			ID:     "tabs-" + number,
+			Title:  precedingHeading(first, reader.Source()),
+			Static: static,
+		}
+
+		var tabs []*Tab
+		for n := ast.Node(first); n != nil; n = n.NextSibling() {
+			tab, ok := n.(*Tab)
+			if !ok {
+				break
+			}
+			tabs = append(tabs, tab)
+		}
+
+		parent := first.Parent()
+		parent.InsertBefore(parent, first, group)
+
+		var names []string
+		selected := tabs[0]
+		for j, tab := range tabs {
+			tab.Name = normalizeTabName(tab.Title)
+			tab.ID = "tab-" + number + "-" + strconv.Itoa(j+1)
+			tab.PanelID = "tab-panel-" + number + "-" + strconv.Itoa(j+1)
+			if tab.Default && !selected.Default {
+				selected = tab
+			}
+			names = append(names, tab.Name)
+			parent.RemoveChild(parent, tab)
+			group.AppendChild(group, tab)
+		}
+		selected.Selected = true
+
+		sort.Strings(names)
+		group.SyncKey = strings.Join(names, ",")
+	}
+}
+
+// precedingHeading returns the text of the heading the node comes after,
+// or "" if there isn't one.
+func precedingHeading(n ast.Node, source []byte) string {
+	if heading := precedingHeadingNode(n); heading != nil {
+		return string(heading.Text(source))
+	}
+	return ""
+}
+
+// precedingHeadingNode returns the heading the node comes after, looking
+// back through its siblings and then its ancestors' siblings.
+func precedingHeadingNode(n ast.Node) *ast.Heading {
+	for p := n; p != nil; p = p.Parent() {
+		if heading := previousHeading(p); heading != nil {
+			return heading
+		}
+	}
+	return nil
+}
+
+// previousHeading returns the closest heading before the node among its
+// siblings, or nil if there isn't one.
+func previousHeading(n ast.Node) *ast.Heading {
+	for s := n.PreviousSibling(); s != nil; s = s.PreviousSibling() {
+		if heading, ok := s.(*ast.Heading); ok {
+			return heading
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package treeblocks
+
+import "github.com/yuin/goldmark/ast"
+
+//-----ast
+
+// TreeKind is TreeBlock
+var TreeKind = ast.NewNodeKind("TreeBlock")
+
+// TreeBlock is a directory tree drawn from an indented list of paths.
+type TreeBlock struct {
+	ast.BaseBlock
+	Entries []Entry
+}
+
+// Entry is one file or folder in a tree.
+type Entry struct {
+	Depth     int    // 0 for top-level entries
+	Branches  string // The lines drawn before the name, e.g. "│   └── "
+	Name      string
+	Comment   string // Text after "#", if any
+	Folder    bool   // Ends with "/" or has entries under it
+	Highlight bool   // Written as **name**
+}
+
+// Kind reports that this is a TreeBlock.
+func (*TreeBlock) Kind() ast.NodeKind { return TreeKind }
+
+// Dump dumps the contents of this block to stdout.
+func (b *TreeBlock) Dump(src []byte, level int) {
+	ast.DumpHelper(b, src, level, nil, nil)
+}
@@ -0,0 +1,22 @@
+package treeblocks
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type treeExtender struct{}
+
+// TreeExtender turns code fences labeled `tree` into directory trees.
+var TreeExtender = &treeExtender{}
+
+func (e *treeExtender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&TreeTransformer{}, 100),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&TreeHTMLRenderer{}, 0),
+	))
+}
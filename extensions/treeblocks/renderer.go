@@ -0,0 +1,59 @@
+package treeblocks
+
+import (
+	h "html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// TreeHTMLRenderer renders directory trees.
+type TreeHTMLRenderer struct{}
+
+func (r *TreeHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(TreeKind, r.Render)
+}
+
+// Render does the actual rendering. The output is plain text inside <pre>,
+// so it can be copied as is; the icons come from the stylesheet.
+func (r *TreeHTMLRenderer) Render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*TreeBlock)
+
+	// Line up the comments one column past the longest entry.
+	width := 0
+	for _, e := range n.Entries {
+		if l := utf8.RuneCountInString(e.Branches + e.Name); l > width {
+			width = l
+		}
+	}
+
+	// nohighlight stops highlight.js from re-coloring the tree, and hljs
+	// still gets it a copy button.
+	w.WriteString("<pre class=\"tree\"><code class=\"nohighlight hljs tree\">")
+	for _, e := range n.Entries {
+		class := "tree-file"
+		if e.Folder {
+			class = "tree-folder"
+		}
+		if e.Highlight {
+			class += " tree-highlight"
+		}
+
+		w.WriteString(e.Branches)
+		w.WriteString("<span class=\"" + class + "\">" + h.EscapeString(e.Name) + "</span>")
+		if e.Comment != "" {
+			padding := width - utf8.RuneCountInString(e.Branches+e.Name) + 2
+			w.WriteString(strings.Repeat(" ", padding))
+			w.WriteString("<span class=\"tree-comment\"># " + h.EscapeString(e.Comment) + "</span>")
+		}
+		w.WriteString("\n")
+	}
+	w.WriteString("</code></pre>\n")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,156 @@
+package treeblocks
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ----- TreeTransformer
+
+// commentPattern matches a "# comment" at the end of a line.
+var commentPattern = regexp.MustCompile(`\s+#\s?(.*)$`)
+
+// TreeTransformer transforms code fences with `tree` labels into TreeBlocks.
+type TreeTransformer struct {
+}
+
+// Transform converts the nodes.
+func (s *TreeTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+
+	// define the types
+	var (
+		treeBlocks []*ast.FencedCodeBlock // the type of block we're looking for
+		_tree      = []byte("tree")       // the code fence label
+	)
+
+	// Collect all blocks to be replaced without modifying the tree.
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+
+		cb, ok := node.(*ast.FencedCodeBlock)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		// if not a tree block, move along.
+		if !bytes.Equal(cb.Language(reader.Source()), _tree) {
+			return ast.WalkContinue, nil
+		}
+
+		treeBlocks = append(treeBlocks, cb)
+		return ast.WalkContinue, nil
+	})
+
+	// replace the old code blocks with the new ones using our type.
+	for _, cb := range treeBlocks {
+		var lines []string
+		for i := 0; i < cb.Lines().Len(); i++ {
+			line := cb.Lines().At(i)
+			lines = append(lines, string(line.Value(reader.Source())))
+		}
+
+		b := &TreeBlock{Entries: parseEntries(lines)}
+		b.SetLines(cb.Lines())
+
+		parent := cb.Parent()
+		if parent != nil {
+			parent.ReplaceChild(parent, cb, b)
+		}
+	}
+}
+
+// parseEntries turns indented lines into entries and draws the branches.
+// Blank lines are skipped. Any deeper indentation than the line
+// before counts as one level down.
+func parseEntries(lines []string) []Entry {
+	var entries []Entry
+	var indents []int // indentation of the current entry's ancestors
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r\n")
+		name := strings.TrimLeft(line, " \t")
+		if name == "" {
+			continue
+		}
+		indent := indentWidth(line[:len(line)-len(name)])
+
+		for len(indents) > 0 && indents[len(indents)-1] >= indent {
+			indents = indents[:len(indents)-1]
+		}
+		e := Entry{Depth: len(indents)}
+		indents = append(indents, indent)
+
+		if m := commentPattern.FindStringSubmatchIndex(name); m != nil {
+			e.Comment = name[m[2]:m[3]]
+			name = name[:m[0]]
+		}
+		if len(name) > 4 && strings.HasPrefix(name, "**") && strings.HasSuffix(name, "**") {
+			e.Highlight = true
+			name = name[2 : len(name)-2]
+		}
+		e.Name = name
+		e.Folder = strings.HasSuffix(name, "/")
+		entries = append(entries, e)
+	}
+
+	for i := range entries {
+		if i+1 < len(entries) && entries[i+1].Depth > entries[i].Depth {
+			entries[i].Folder = true
+		}
+	}
+
+	// last[d] is true when the most recent entry at depth d is the last of its siblings.
+	var last []bool
+	for i := range entries {
+		e := &entries[i]
+		isLast := true
+		for _, next := range entries[i+1:] {
+			if next.Depth <= e.Depth {
+				isLast = next.Depth < e.Depth
+				break
+			}
+		}
+
+		last = append(last[:e.Depth], isLast)
+		if e.Depth == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		for d := 1; d < e.Depth; d++ {
+			if last[d] {
+				b.WriteString("    ")
+			} else {
+				b.WriteString("│   ")
+			}
+		}
+		if isLast {
+			b.WriteString("└── ")
+		} else {
+			b.WriteString("├── ")
+		}
+		e.Branches = b.String()
+	}
+
+	return entries
+}
+
+// indentWidth returns the width of the leading whitespace, counting tabs as four spaces.
+func indentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
@@ -0,0 +1,48 @@
+package xref
+
+import (
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// CrossRef is a reference like [@sec:middleware] or [@fig:arch] to a
+// heading or figure elsewhere in the document.
+type CrossRef struct {
+	ast.BaseInline
+	Target    string // As written, e.g. "sec:middleware"
+	ShowTitle bool   // Use the target's title instead of its label
+	Offset    int    // Position in the source, for error messages
+
+	// Set by the transformer.
+	Href     string
+	LinkText string
+}
+
+// Dump implements Node.Dump.
+func (n *CrossRef) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Target":    n.Target,
+		"ShowTitle": strconv.FormatBool(n.ShowTitle),
+		"Href":      n.Href,
+		"LinkText":  n.LinkText,
+	}, nil)
+}
+
+// KindCrossRef is a NodeKind of the CrossRef node.
+var KindCrossRef = ast.NewNodeKind("CrossRef")
+
+// Kind implements Node.Kind.
+func (n *CrossRef) Kind() ast.NodeKind {
+	return KindCrossRef
+}
+
+// NewCrossRef returns a new CrossRef node.
+func NewCrossRef(target string, showTitle bool, offset int) *CrossRef {
+	return &CrossRef{Target: target, ShowTitle: showTitle, Offset: offset}
+}
+
+// PrefixIDs adds prefix to the id the reference links to.
+func (n *CrossRef) PrefixIDs(prefix string) {
+	n.Href = prefix + n.Href
+}
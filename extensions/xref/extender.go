@@ -0,0 +1,31 @@
+package xref
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extender resolves references like '[@sec:middleware]' and '[@fig:arch]'
+// to links. References to headings are labeled with the heading text, or
+// with the section number, like "Section 2.3", when NumberedHeadings is set.
+type Extender struct {
+	NumberedHeadings bool
+}
+
+func (e *Extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// Runs before the link parser so "[@" isn't treated as a link.
+		parser.WithInlineParsers(
+			util.Prioritized(NewCrossRefParser(), 150),
+		),
+		// Runs after the figures transformer so figure ids are known.
+		parser.WithASTTransformers(
+			util.Prioritized(&CrossRefTransformer{NumberedHeadings: e.NumberedHeadings}, 300),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(NewCrossRefHTMLRenderer(), 0),
+	))
+}
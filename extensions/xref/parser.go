@@ -0,0 +1,51 @@
+package xref
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// refPattern matches the inside of a reference: an id, optionally followed by "title".
+var refPattern = regexp.MustCompile(`^@([A-Za-z0-9_.:\-]+)( title)?$`)
+
+type xrefParser struct {
+}
+
+var defaultCrossRefParser = &xrefParser{}
+
+// NewCrossRefParser return a new InlineParser that parses cross-references.
+func NewCrossRefParser() parser.InlineParser {
+	return defaultCrossRefParser
+}
+
+func (s *xrefParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (s *xrefParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("[@")) {
+		return nil
+	}
+
+	end := bytes.IndexByte(line, ']')
+	if end < 0 {
+		return nil
+	}
+
+	m := refPattern.FindSubmatch(line[1:end])
+	if m == nil {
+		return nil
+	}
+
+	block.Advance(end + 1)
+	return NewCrossRef(string(m[1]), len(m[2]) > 0, segment.Start)
+}
+
+func (s *xrefParser) CloseBlock(parent ast.Node, pc parser.Context) {
+	// nothing to do
+}
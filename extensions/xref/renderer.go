@@ -0,0 +1,41 @@
+package xref
+
+import (
+	h "html"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// CrossRefHTMLRenderer is a renderer.NodeRenderer implementation that
+// renders CrossRef nodes.
+type CrossRefHTMLRenderer struct {
+	html.Config
+}
+
+// NewCrossRefHTMLRenderer returns a new CrossRefHTMLRenderer.
+func NewCrossRefHTMLRenderer(opts ...html.Option) renderer.NodeRenderer {
+	r := &CrossRefHTMLRenderer{
+		Config: html.NewConfig(),
+	}
+	for _, opt := range opts {
+		opt.SetHTMLOption(&r.Config)
+	}
+	return r
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *CrossRefHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindCrossRef, r.renderCrossRef)
+}
+
+func (r *CrossRefHTMLRenderer) renderCrossRef(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*CrossRef)
+	_, _ = w.WriteString("<a class=\"xref\" href=\"#" + h.EscapeString(n.Href) + "\">" + h.EscapeString(n.LinkText) + "</a>")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,124 @@
+package xref
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"lessonmd/extensions/figures"
+	"lessonmd/extensions/headingnumbers"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+var errorContextKey = parser.NewContextKey()
+
+// Error returns an error describing every reference that could not be
+// resolved while parsing a document, or nil if they all resolved.
+func Error(pc parser.Context) error {
+	v := pc.Get(errorContextKey)
+	if v == nil {
+		return nil
+	}
+	return v.(error)
+}
+
+// target is something a reference can point at.
+type target struct {
+	id    string
+	label string // e.g. "Middleware", "Section 2.3", or "Figure 1"
+	title string
+}
+
+// ----- CrossRefTransformer
+
+// CrossRefTransformer resolves each reference against the heading ids and
+// figure ids in the document. Headings are only labeled by number when
+// NumberedHeadings is set, since otherwise the numbers aren't on the page.
+type CrossRefTransformer struct {
+	NumberedHeadings bool
+}
+
+// Transform converts the nodes.
+func (s *CrossRefTransformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var (
+		refs     []*CrossRef
+		headings []*ast.Heading
+		targets  = map[string]target{}
+		sections = map[string]target{}
+		source   = reader.Source()
+	)
+
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *CrossRef:
+			refs = append(refs, n)
+		case *ast.Heading:
+			headings = append(headings, n)
+		case *figures.Figure:
+			targets[n.ID] = target{id: n.ID, label: n.Label(), title: captionText(n, source)}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// Nothing to do if there were no references found.
+	if len(refs) == 0 {
+		return
+	}
+
+	numbers := headingnumbers.SectionNumbers(headings)
+	for i, heading := range headings {
+		id, ok := heading.AttributeString("id")
+		if !ok {
+			continue
+		}
+		t := target{id: string(id.([]byte)), title: string(heading.Text(source))}
+		t.label = t.title
+		if s.NumberedHeadings && numbers[i] != "" {
+			t.label = "Section " + numbers[i]
+		}
+		sections[t.id] = t
+		if _, ok := targets[t.id]; !ok {
+			targets[t.id] = t
+		}
+	}
+
+	var errs []string
+	for _, ref := range refs {
+		t, ok := targets[ref.Target]
+		if !ok && strings.HasPrefix(ref.Target, "sec:") {
+			t, ok = sections[strings.TrimPrefix(ref.Target, "sec:")]
+		}
+		if !ok {
+			line := bytes.Count(source[:ref.Offset], []byte("\n")) + 1
+			errs = append(errs, fmt.Sprintf("line %d: unknown cross-reference target %q", line, ref.Target))
+			ref.Href, ref.LinkText = ref.Target, ref.Target
+			continue
+		}
+
+		ref.Href = t.id
+		ref.LinkText = t.label
+		if ref.ShowTitle && t.title != "" {
+			ref.LinkText = t.title
+		}
+	}
+
+	if len(errs) > 0 {
+		pctx.Set(errorContextKey, errors.New(strings.Join(errs, "\n")))
+	}
+}
+
+// captionText returns the plain text of a figure's caption.
+func captionText(f *figures.Figure, source []byte) string {
+	for c := f.FirstChild(); c != nil; c = c.NextSibling() {
+		if caption, ok := c.(*figures.Caption); ok {
+			return strings.TrimSpace(string(caption.Text(source)))
+		}
+	}
+	return ""
+}
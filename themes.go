@@ -0,0 +1,259 @@
+package lessonmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// themeColors lists the color variables every theme sets, in the order they're written.
+// The stylesheet uses them as var(--lessonmd-NAME).
+var themeColors = []string{
+	"text", "background", "muted", "link", "accent", "accent-text",
+	"border", "rule", "shadow", "surface", "surface-border", "surface-hover",
+	"code-text", "code-background", "code-border", "quote-border",
+	"table-header-text", "table-header-background", "table-stripe",
+	"highlight", "highlight-text", "output-background",
+	"diff-add", "diff-del", "diff-hunk",
+	"note-text", "note-background", "note-border",
+	"tip-text", "tip-background", "tip-border",
+	"info-text", "info-background", "info-border",
+	"caution-text", "caution-background", "caution-border",
+	"warning-text", "warning-background", "warning-border",
+	"chart-1", "chart-2", "chart-3", "chart-4", "chart-5", "chart-6",
+}
+
+var lightTheme = map[string]string{
+	"text":                    "#333",
+	"background":              "#fff",
+	"muted":                   "#6a737d",
+	"link":                    "#0969da",
+	"accent":                  "#0969da",
+	"accent-text":             "#fff",
+	"border":                  "#ddd",
+	"rule":                    "#eaecef",
+	"shadow":                  "#ddd",
+	"surface":                 "#f6f8fa",
+	"surface-border":          "#d0d7de",
+	"surface-hover":           "#e9ecef",
+	"code-text":               "#000",
+	"code-background":         "#fafafa",
+	"code-border":             "#e1e1e8",
+	"quote-border":            "#dfe2e5",
+	"table-header-text":       "#ddd",
+	"table-header-background": "#333",
+	"table-stripe":            "#e5e5e5",
+	"highlight":               "#fff8c5",
+	"highlight-text":          "#24292f",
+	"output-background":       "#ddd",
+	"diff-add":                "#e6ffec",
+	"diff-del":                "#ffebe9",
+	"diff-hunk":               "#ddf4ff",
+	"note-text":               "rgb(71, 71, 72)",
+	"note-background":         "rgb(253, 253, 254)",
+	"note-border":             "rgb(212, 213, 216)",
+	"tip-text":                "rgb(0, 49, 0)",
+	"tip-background":          "rgb(230, 246, 230)",
+	"tip-border":              "rgb(0, 148, 0)",
+	"info-text":               "rgb(25, 60, 71)",
+	"info-background":         "rgb(238, 249, 253)",
+	"info-border":             "rgb(76, 179, 212)",
+	"caution-text":            "rgb(77, 56, 0)",
+	"caution-background":      "#fff8e6",
+	"caution-border":          "#e6a700",
+	"warning-text":            "#4b1113",
+	"warning-background":      "#ffebec",
+	"warning-border":          "#e13238",
+	"chart-1":                 "#0969da",
+	"chart-2":                 "#bc4c00",
+	"chart-3":                 "#1a7f37",
+	"chart-4":                 "#8250df",
+	"chart-5":                 "#cf222e",
+	"chart-6":                 "#1b7c83",
+}
+
+var darkTheme = map[string]string{
+	"text":                    "#e6edf3",
+	"background":              "#0d1117",
+	"muted":                   "#8b949e",
+	"link":                    "#4493f8",
+	"accent":                  "#1f6feb",
+	"accent-text":             "#fff",
+	"border":                  "#30363d",
+	"rule":                    "#21262d",
+	"shadow":                  "#010409",
+	"surface":                 "#161b22",
+	"surface-border":          "#30363d",
+	"surface-hover":           "#21262d",
+	"code-text":               "#e6edf3",
+	"code-background":         "#161b22",
+	"code-border":             "#30363d",
+	"quote-border":            "#3d444d",
+	"table-header-text":       "#e6edf3",
+	"table-header-background": "#21262d",
+	"table-stripe":            "#161b22",
+	"highlight":               "#614c00",
+	"highlight-text":          "#e6edf3",
+	"output-background":       "#21262d",
+	"diff-add":                "#12261e",
+	"diff-del":                "#25171c",
+	"diff-hunk":               "#121d2f",
+	"note-text":               "#c9d1d9",
+	"note-background":         "#161b22",
+	"note-border":             "#6e7681",
+	"tip-text":                "#aff5b4",
+	"tip-background":          "#0f2417",
+	"tip-border":              "#2ea043",
+	"info-text":               "#b6e3f4",
+	"info-background":         "#0c2d3b",
+	"info-border":             "#4cb3d4",
+	"caution-text":            "#f8e3a1",
+	"caution-background":      "#2e2200",
+	"caution-border":          "#d29922",
+	"warning-text":            "#ffdcd7",
+	"warning-background":      "#2d0f10",
+	"warning-border":          "#f85149",
+	"chart-1":                 "#4493f8",
+	"chart-2":                 "#f0883e",
+	"chart-3":                 "#3fb950",
+	"chart-4":                 "#a371f7",
+	"chart-5":                 "#f85149",
+	"chart-6":                 "#39c5cf",
+}
+
+var highContrastTheme = map[string]string{
+	"text":                    "#000",
+	"background":              "#fff",
+	"muted":                   "#333",
+	"link":                    "#0030a8",
+	"accent":                  "#0030a8",
+	"accent-text":             "#fff",
+	"border":                  "#000",
+	"rule":                    "#000",
+	"shadow":                  "#000",
+	"surface":                 "#fff",
+	"surface-border":          "#000",
+	"surface-hover":           "#ddd",
+	"code-text":               "#000",
+	"code-background":         "#fff",
+	"code-border":             "#000",
+	"quote-border":            "#000",
+	"table-header-text":       "#fff",
+	"table-header-background": "#000",
+	"table-stripe":            "#eee",
+	"highlight":               "#ff0",
+	"highlight-text":          "#000",
+	"output-background":       "#eee",
+	"diff-add":                "#cfc",
+	"diff-del":                "#fcc",
+	"diff-hunk":               "#cce5ff",
+	"note-text":               "#000",
+	"note-background":         "#fff",
+	"note-border":             "#000",
+	"tip-text":                "#000",
+	"tip-background":          "#fff",
+	"tip-border":              "#006400",
+	"info-text":               "#000",
+	"info-background":         "#fff",
+	"info-border":             "#00509e",
+	"caution-text":            "#000",
+	"caution-background":      "#fff",
+	"caution-border":          "#8a6100",
+	"warning-text":            "#000",
+	"warning-background":      "#fff",
+	"warning-border":          "#b00000",
+	"chart-1":                 "#0030a8",
+	"chart-2":                 "#a04000",
+	"chart-3":                 "#006400",
+	"chart-4":                 "#6a00a8",
+	"chart-5":                 "#b00000",
+	"chart-6":                 "#005f66",
+}
+
+// themes are the built-in themes. "auto" is light or dark depending on
+// the reader's prefers-color-scheme setting.
+var themes = map[string]map[string]string{
+	"light":         lightTheme,
+	"dark":          darkTheme,
+	"high-contrast": highContrastTheme,
+	"auto":          lightTheme,
+}
+
+// ThemeNames returns the names of the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ThemeColorNames returns the names of the colors a theme sets, which can
+// be overridden with ConverterOptions.ThemeColors.
+func ThemeColorNames() []string {
+	return append([]string(nil), themeColors...)
+}
+
+// checkTheme returns an error if the theme or any color override is unknown,
+// or if a color value could break out of the stylesheet.
+func checkTheme(theme string, colors map[string]string) error {
+	if _, ok := themes[theme]; !ok && theme != "" {
+		return fmt.Errorf("unknown theme %q (available: %s)", theme, strings.Join(ThemeNames(), ", "))
+	}
+	for name, value := range colors {
+		if _, ok := lightTheme[name]; !ok {
+			return fmt.Errorf("unknown theme color %q (available: %s)", name, strings.Join(themeColors, ", "))
+		}
+		if value == "" || strings.ContainsAny(value, ";{}<>\\\"'") {
+			return fmt.Errorf("invalid value %q for theme color %q", value, name)
+		}
+	}
+	return nil
+}
+
+// themeVariables returns the CSS custom properties for the theme, followed
+// by the color overrides. The theme must already be checked.
+func themeVariables(theme string, colors map[string]string) string {
+	if theme == "" {
+		theme = "light"
+	}
+
+	var b strings.Builder
+	writeColors(&b, themes[theme])
+	if theme == "auto" {
+		b.WriteString("@media (prefers-color-scheme: dark) {\n")
+		writeColors(&b, darkTheme)
+		b.WriteString("}\n")
+	}
+	if len(colors) > 0 {
+		writeColors(&b, colors)
+	}
+	return b.String()
+}
+
+// writeColors writes a rule that sets the colors, in the order of themeColors.
+func writeColors(b *strings.Builder, colors map[string]string) {
+	b.WriteString(".item {\n")
+	for _, name := range themeColors {
+		if value, ok := colors[name]; ok {
+			b.WriteString("  --lessonmd-" + name + ": " + value + ";\n")
+		}
+	}
+	b.WriteString("}\n")
+}
+
+// printVariables returns the colors to print with. Browsers usually drop
+// background colors when printing, so dark themes print with light colors.
+func printVariables(theme string, colors map[string]string) string {
+	if theme != "dark" && theme != "auto" {
+		return ""
+	}
+
+	var b strings.Builder
+	writeColors(&b, lightTheme)
+	if len(colors) > 0 {
+		writeColors(&b, colors)
+	}
+	return b.String()
+}